@@ -0,0 +1,142 @@
+package recipe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"main/utils/unit"
+	"net/http"
+)
+
+// CookingApi 定义一个烹饪模式API的结构体
+type CookingApi struct{}
+
+// Steps 获取菜谱在烹饪模式下的所有步骤，包括每个步骤的计时器和温度
+func (a *CookingApi) Steps(c *gin.Context) {
+	mode, ok := a.getCookingMode(c)
+	if !ok {
+		return
+	}
+
+	// 返回成功的响应，包括所有的步骤
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get cooking steps successfully",
+		"ok":   true,
+		"data": mode,
+	})
+}
+
+// Step 获取菜谱在烹饪模式下的第N个步骤，N从1开始
+func (a *CookingApi) Step(c *gin.Context) {
+	// 从路径中获取步骤的序号，并将其转换为整数
+	n := cast.ToInt(c.Param("step"))
+
+	// 如果步骤的序号小于等于0，返回错误
+	if n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "step"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	mode, ok := a.getCookingMode(c)
+	if !ok {
+		return
+	}
+
+	// 获取第N个步骤
+	step, err := service.Recipe().Info().GetCookingStep(mode, n)
+	if err != nil {
+		switch err.Error() {
+		case "step not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+		return
+	}
+
+	// 只返回这一个步骤，总步骤数用于客户端翻页
+	mode.Steps = []*model.CookingStep{step}
+
+	// 返回成功的响应，包括步骤和总步骤数
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get cooking step successfully",
+		"ok":   true,
+		"data": mode,
+	})
+}
+
+// getCookingMode 获取请求的菜谱并转换为烹饪模式，出现错误时返回响应和false
+func (a *CookingApi) getCookingMode(c *gin.Context) (*model.CookingMode, bool) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	// 从请求中获取单位制，为空时使用用户默认的单位制
+	units := c.Query("units")
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return nil, false
+	}
+	// 如果单位制无效，返回错误
+	if units != "" && !unit.IsValid(units) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "units"`,
+			"ok":   false,
+		})
+		return nil, false
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "recipe not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+		return nil, false
+	}
+
+	// 没有指定单位制时，使用用户默认的单位制
+	if units == "" {
+		units, err = service.User().User().GetUnits(c, userId)
+		if err != nil {
+			switch err.Error() {
+			case "internal err":
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code": http.StatusInternalServerError,
+					"msg":  "internal err",
+					"ok":   false,
+				})
+			}
+			return nil, false
+		}
+	}
+
+	return service.Recipe().Info().GetCookingMode(recipe, units), true
+}
@@ -8,3 +8,45 @@ var insRecipe = Api{}
 func (g *Group) Recipe() *Api {
 	return &insRecipe
 }
+
+// insManage 创建一个管理用户菜谱API的实例
+var insManage = ManageApi{}
+
+func (g *Group) Manage() *ManageApi {
+	return &insManage
+}
+
+// insExport 创建一个导出菜谱API的实例
+var insExport = ExportApi{}
+
+func (g *Group) Export() *ExportApi {
+	return &insExport
+}
+
+// insReview 创建一个菜谱评价API的实例
+var insReview = ReviewApi{}
+
+func (g *Group) Review() *ReviewApi {
+	return &insReview
+}
+
+// insImage 创建一个菜谱图片API的实例
+var insImage = ImageApi{}
+
+func (g *Group) Image() *ImageApi {
+	return &insImage
+}
+
+// insCooking 创建一个烹饪模式API的实例
+var insCooking = CookingApi{}
+
+func (g *Group) Cooking() *CookingApi {
+	return &insCooking
+}
+
+// insVersion 创建一个菜谱版本历史API的实例
+var insVersion = VersionApi{}
+
+func (g *Group) Version() *VersionApi {
+	return &insVersion
+}
@@ -0,0 +1,124 @@
+package recipe
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/service"
+	"main/utils/unit"
+	"net/http"
+)
+
+// ExportApi 定义一个导出菜谱API的结构体
+type ExportApi struct{}
+
+// Export 将菜谱导出为schema.org的JSON-LD、Markdown或者纯文本
+func (a *ExportApi) Export(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	// 从请求中获取导出的格式和是否下载
+	format := c.DefaultQuery("format", "jsonld")
+	download := cast.ToBool(c.Query("download"))
+	// 从请求中获取份数，为空时不缩放
+	servings := cast.ToInt64(c.Query("servings"))
+	// 从请求中获取单位制，为空时使用用户默认的单位制
+	units := c.Query("units")
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果导出的格式无效，返回错误
+	if format != "jsonld" && format != "markdown" && format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "format"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果份数无效，返回错误
+	if servings < 0 || servings > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "servings"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果单位制无效，返回错误
+	if units != "" && !unit.IsValid(units) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "units"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "recipe not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 没有指定单位制时，使用用户默认的单位制
+	if units == "" {
+		units, err = service.User().User().GetUnits(c, userId)
+		if err != nil {
+			switch err.Error() {
+			case "internal err":
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code": http.StatusInternalServerError,
+					"msg":  "internal err",
+					"ok":   false,
+				})
+			}
+
+			return
+		}
+	}
+
+	// 按份数缩放菜谱，并转换到指定的单位制
+	parsed := service.Recipe().Info().ScaleRecipe(recipe, servings)
+	service.Recipe().Info().ConvertRecipe(recipe, parsed, units)
+
+	// 如果需要下载，设置下载的文件名
+	if download {
+		ext := map[string]string{"jsonld": "jsonld", "markdown": "md", "text": "txt"}[format]
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="recipe-%d.%s"`, recipeId, ext))
+	}
+
+	// 根据格式导出菜谱
+	switch format {
+	case "jsonld":
+		c.Header("Content-Type", "application/ld+json; charset=utf-8")
+		c.JSON(http.StatusOK, service.Recipe().Export().ToJSONLD(recipe))
+	case "markdown":
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(service.Recipe().Export().ToMarkdown(recipe)))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(service.Recipe().Export().ToText(recipe)))
+	}
+}
@@ -0,0 +1,177 @@
+package recipe
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"main/app/internal/service/recipe"
+	"mime/multipart"
+	"net/http"
+)
+
+// ImageApi 定义一个菜谱图片API的结构体
+type ImageApi struct{}
+
+// Upload 上传用户自己菜谱的图片，图片放在multipart表单的images字段中，可以一次上传多张
+func (a *ImageApi) Upload(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户自己的菜谱
+	r, err := service.Recipe().Manage().GetOwnRecipe(c, userId, recipeId)
+	if err != nil {
+		responseImageErr(c, err)
+		return
+	}
+
+	// 限制请求的大小，最多为所有图片的大小加上表单的其他内容
+	maxSize := g.Config.Upload.GetMaxSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize*recipe.MaxImages+1<<20)
+
+	// 解析multipart表单
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code": http.StatusRequestEntityTooLarge,
+				"msg":  "request too large",
+				"ok":   false,
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "invalid form",
+			"ok":   false,
+		})
+		return
+	}
+	defer form.RemoveAll()
+
+	// 兼容只上传一张图片时使用image字段
+	files := append(form.File["images"], form.File["image"]...)
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "image cannot be null",
+			"ok":   false,
+		})
+		return
+	}
+	if len(r.Images)+len(files) > recipe.MaxImages {
+		responseImageErr(c, errors.New("too many images"))
+		return
+	}
+
+	// 逐张检查并保存图片，出现错误时删除已经保存的图片
+	images := make([]*model.RecipeImage, 0, len(files))
+	for _, header := range files {
+		img, err := saveImage(c, recipeId, header)
+		if err != nil {
+			for _, saved := range images {
+				service.Recipe().Image().DeleteImage(c, saved)
+			}
+			responseImageErr(c, err)
+			return
+		}
+		images = append(images, img)
+	}
+
+	// 将图片添加到菜谱中
+	if err = service.Recipe().Image().AddImages(c, r, images); err != nil {
+		for _, saved := range images {
+			service.Recipe().Image().DeleteImage(c, saved)
+		}
+		responseImageErr(c, err)
+		return
+	}
+
+	// 返回成功的响应，包括图片和缩略图的URL
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "upload images successfully",
+		"ok":   true,
+		"data": images,
+	})
+}
+
+// saveImage 检查上传的图片并保存原图和缩略图
+func saveImage(c *gin.Context, recipeId int64, header *multipart.FileHeader) (*model.RecipeImage, error) {
+	if header.Size > g.Config.Upload.GetMaxSize() {
+		return nil, errors.New("image too large")
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		g.Logger.Errorf("open upload file failed. err: %v", err)
+		return nil, errors.New("internal err")
+	}
+	defer f.Close()
+
+	// 根据内容判断图片的类型，并保存图片
+	data, contentType, err := service.Recipe().Image().ReadImage(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Recipe().Image().SaveImage(c, recipeId, data, contentType)
+}
+
+// responseImageErr 根据上传图片时出现的错误返回对应的响应
+func responseImageErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "recipe not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": http.StatusForbidden,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "image too large":
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"code": http.StatusRequestEntityTooLarge,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "unsupported image type":
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"code": http.StatusUnsupportedMediaType,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "invalid image", "image dimensions too large", "too many images":
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
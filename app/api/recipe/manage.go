@@ -0,0 +1,237 @@
+package recipe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"net/http"
+)
+
+// ManageApi 定义一个管理用户菜谱API的结构体
+type ManageApi struct{}
+
+// Create 创建用户的菜谱
+func (a *ManageApi) Create(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 将请求的内容解析为菜谱的表单
+	form := &model.RecipeForm{}
+	if err := c.ShouldBind(form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "invalid recipe",
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查菜谱是否有效
+	if err := service.Recipe().Manage().ValidateRecipe(form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 在数据库中创建菜谱
+	recipe, err := service.Recipe().Manage().CreateRecipe(c, userId, form)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 返回成功的响应，包括创建的菜谱
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "create recipe successfully",
+		"ok":   true,
+		"data": recipe,
+	})
+}
+
+// Update 更新用户的菜谱
+func (a *ManageApi) Update(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 将请求的内容解析为菜谱的表单
+	form := &model.RecipeForm{}
+	if err := c.ShouldBind(form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "invalid recipe",
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查菜谱是否有效
+	if err := service.Recipe().Manage().ValidateRecipe(form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户自己的菜谱
+	recipe, err := service.Recipe().Manage().GetOwnRecipe(c, userId, recipeId)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+
+	// 在数据库中更新菜谱
+	err = service.Recipe().Manage().UpdateRecipe(c, recipe, form)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+
+	// 返回成功的响应，包括更新后的菜谱
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "update recipe successfully",
+		"ok":   true,
+		"data": recipe,
+	})
+}
+
+// Delete 删除用户的菜谱
+func (a *ManageApi) Delete(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户自己的菜谱
+	recipe, err := service.Recipe().Manage().GetOwnRecipe(c, userId, recipeId)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+
+	// 删除前获取菜谱当前和历史版本中的图片，失败时只删除当前的图片
+	images, _ := service.Recipe().Version().GetVersionImages(c, recipeId)
+	images = append(images, recipe.Images...)
+
+	// 在数据库中删除菜谱
+	err = service.Recipe().Manage().DeleteRecipe(c, recipeId)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+	// 删除菜谱的评价、版本历史、收藏、膳食计划和图片，失败时只记录日志
+	// 购物清单中的食材在生成时已经复制，不引用菜谱，不需要删除
+	_ = service.Recipe().Review().DeleteReviewsByRecipe(c, recipeId)
+	_ = service.Recipe().Version().DeleteVersions(c, recipeId)
+	_ = service.User().Collect().DeleteRecipeCollections(c, recipeId)
+	_ = service.User().Plan().DeletePlansByRecipe(c, recipeId)
+	service.Recipe().Image().DeleteRecipeImages(c, recipeId, images)
+
+	// 返回成功的响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "delete recipe successfully",
+		"ok":   true,
+	})
+}
+
+// Fork 将菜谱复制为用户自己的私有菜谱，复制后的菜谱记录来源的菜谱ID
+func (a *ManageApi) Fork(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	source, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+
+	// 在数据库中创建复制的菜谱
+	recipe, err := service.Recipe().Manage().ForkRecipe(c, userId, source)
+	if err != nil {
+		responseManageErr(c, err)
+		return
+	}
+
+	// 返回成功的响应，包括复制的菜谱
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "fork recipe successfully",
+		"ok":   true,
+		"data": recipe,
+	})
+}
+
+// responseManageErr 根据管理菜谱时出现的错误返回对应的响应
+func responseManageErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "recipe not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": http.StatusForbidden,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
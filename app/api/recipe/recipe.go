@@ -1,17 +1,21 @@
 package recipe
 
 import (
-	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cast"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	g "main/app/global"
 	"main/app/internal/model"
 	"main/app/internal/service"
+	"main/app/internal/service/recipe"
+	"main/utils/unit"
+	"math"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 // Api 定义一个API的结构体
@@ -19,31 +23,31 @@ type Api struct{}
 
 // Search 搜索菜谱
 func (a *Api) Search(c *gin.Context) {
-	// 从请求中获取饮食习惯、烹饪时间、准备时间、总时间、口味和食材
-	dietary := c.Query("dietary")
-	cookTimeString := c.Query("cook_time")
-	perpTimeString := c.Query("perp_time")
-	totalTimeString := c.Query("total_time")
-	taste := c.QueryArray("taste")
-	ingredients := c.QueryArray("ingredients")
+	// 从请求中获取搜索词、排序字段、排序方向和分页游标
+	q := c.Query("q")
+	sortKey := c.Query("sort")
+	order := c.Query("order")
+	cursor := c.Query("cursor")
 
-	// 如果饮食习惯不为空并且不是halal、vegan或vegetarian，返回错误
-	if dietary != "" {
-		if dietary != "halal" && dietary != "vegan" && dietary != "vegetarian" {
+	// 从请求中获取需要统计的字段，例如facets=category,dietary,total_time
+	var facetNames []string
+	for _, name := range strings.Split(c.Query("facets"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		// 如果统计字段无效，返回错误
+		if !service.Recipe().Search().CheckFacet(name) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"code": http.StatusBadRequest,
-				"msg":  "invalid dietary",
+				"msg":  fmt.Sprintf(`invalid facet "%s"`, name),
 				"ok":   false,
 			})
 			return
 		}
+		facetNames = append(facetNames, name)
 	}
 
-	// 获取烹饪时间、准备时间和总时间的时间段
-	cookBeginTime, cookEndTime := service.Recipe().Info().GetTimeDuration(cookTimeString)
-	perpBeginTime, perpEndTime := service.Recipe().Info().GetTimeDuration(perpTimeString)
-	totalBeginTime, totalEndTime := service.Recipe().Info().GetTimeDuration(totalTimeString)
-
 	// 从请求中获取限制数和页数，并将它们转换为整数
 	limit := cast.ToInt64(c.Query("limit"))
 	page := cast.ToInt64(c.Query("page"))
@@ -57,8 +61,8 @@ func (a *Api) Search(c *gin.Context) {
 		})
 		return
 	}
-	// 如果页数小于等于0，返回错误
-	if page <= 0 {
+	// 如果没有使用游标并且页数小于等于0，返回错误
+	if cursor == "" && page <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": http.StatusBadRequest,
 			"msg":  `invalid param "page"`,
@@ -67,112 +71,197 @@ func (a *Api) Search(c *gin.Context) {
 		return
 	}
 
-	// 获取数据库
-	database := g.MongoDB.Database("food")
+	// 根据请求中的参数生成过滤器
+	filter, err := a.getSearchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
 
-	// 获取菜谱的集合
-	collection := database.Collection("recipe")
+	// 获取排序条件
+	sort, err := service.Recipe().Search().GetSort(sortKey, order, q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
 
-	// 定义一个过滤器
-	filter := bson.D{}
+	// 打印过滤器的内容
+	g.Logger.Debugf("%v", filter)
 
-	// 如果饮食习惯不为空
-	if dietary != "" {
-		// 如果饮食习惯是halal
-		if dietary == "halal" {
-			// 在过滤器中添加饮食习惯的条件，排除非halal的菜谱
-			filter = append(filter, bson.E{
-				Key: "dietary",
-				Value: bson.D{
-					{
-						"$not",
-						bson.D{
-							{
-								"$in",
-								[]string{"non-halal"},
-							},
-						},
-					},
-				},
-			})
-			// 如果饮食习惯是vegetarian
-		} else if dietary == "vegetarian" {
-			// 在过滤器中添加饮食习惯的条件，排除非vegetarian的菜谱
-			filter = append(filter, bson.E{
-				Key: "dietary",
-				Value: bson.D{
-					{
-						"$nin",
-						[]string{"non-vegetarian"},
-					},
-				},
-			})
-			// 如果饮食习惯是vegan
-		} else if dietary == "vegan" {
-			// 在过滤器中添加饮食习惯的条件，排除非vegan的菜谱
-			filter = append(filter, bson.E{
-				Key: "dietary",
-				Value: bson.D{
-					{
-						"$nin",
-						[]string{"non-vegan"},
-					},
-				},
+	// 使用同一个过滤器计算匹配的菜谱总数
+	total, err := service.Recipe().Search().CountRecipes(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+		return
+	}
+
+	// 计算页数
+	pageCount := total / limit
+	if total%limit > 0 {
+		pageCount = pageCount + 1
+	}
+
+	// 创建一个查找选项，多查找一条用于判断是否还有下一页
+	option := &options.FindOptions{}
+	option.SetLimit(limit + 1)
+	option.SetSort(sort)
+	// 如果搜索词不为空，返回相关度得分
+	if q != "" {
+		option.SetProjection(bson.D{{Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}})
+	}
+
+	// 定义查找使用的过滤器
+	findFilter := filter
+	if cursor != "" {
+		// 如果使用游标，从游标之后开始查找
+		cond, err := service.Recipe().Search().GetCursorFilter(sort, cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  err.Error(),
+				"ok":   false,
 			})
+			return
 		}
+		findFilter = append(bson.D{cond}, filter...)
+	} else {
+		// 否则，跳过前面的页
+		option.SetSkip(limit * (page - 1))
 	}
 
-	if cookEndTime != 0 {
-		// 如果烹饪结束时间不为0，将烹饪时间添加到过滤器中
-		filter = append(filter, bson.E{
-			Key: "cook_time",
-			Value: bson.D{
-				{
-					"$gte",
-					cookBeginTime.Seconds(),
-				},
-				{
-					"$lte",
-					cookEndTime.Seconds(),
-				},
-			},
+	// 在集合中查找匹配的菜谱
+	results, err := service.Recipe().Search().FindRecipes(c, findFilter, option)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
 		})
+		return
+	}
+
+	// 如果查找到的菜谱多于限制数，说明还有下一页
+	hasNext := int64(len(results)) > limit
+	nextCursor := ""
+	if hasNext {
+		results = results[:limit]
+		// 按相关度排序时不支持游标
+		if sort[0].Key != recipe.SortFields["relevance"] {
+			nextCursor = service.Recipe().Search().EncodeCursor(sort, results[len(results)-1])
+		}
+	}
+
+	// 定义成功响应，包括菜谱的列表和分页信息
+	res := gin.H{
+		"code":        http.StatusOK,
+		"msg":         "get recipe successfully",
+		"ok":          true,
+		"data":        results,
+		"total":       total,
+		"page_count":  pageCount,
+		"has_next":    hasNext,
+		"next_cursor": nextCursor,
 	}
 
-	if perpEndTime != 0 {
-		// 如果准备结束时间不为0，将准备时间添加到过滤器中
+	// 如果需要统计，使用同一个过滤器统计每个字段的分布
+	if len(facetNames) > 0 {
+		facets, err := service.Recipe().Search().GetFacets(c, filter, facetNames)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+			return
+		}
+		res["facets"] = facets
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, res)
+}
+
+// getSearchFilter 根据请求中的参数生成搜索菜谱的过滤器
+func (a *Api) getSearchFilter(c *gin.Context) (bson.D, error) {
+	// 从请求中获取搜索词、饮食习惯、烹饪时间、准备时间、总时间、口味、食材、排除的食材和过敏原
+	q := c.Query("q")
+	dietary := c.Query("dietary")
+	cookTimeString := c.Query("cook_time")
+	perpTimeString := c.Query("perp_time")
+	totalTimeString := c.Query("total_time")
+	taste := c.QueryArray("taste")
+	ingredients := c.QueryArray("ingredients")
+	excludeIngredients := c.QueryArray("exclude_ingredients")
+	allergens := c.QueryArray("allergens")
+
+	// 定义一个过滤器，排除其他用户的私有菜谱
+	filter := bson.D{service.Recipe().Search().GetVisibilityFilter(c.GetInt64("id"))}
+
+	// 如果搜索词不为空，使用全文索引匹配名称、描述、关键词和食材
+	if q != "" {
 		filter = append(filter, bson.E{
-			Key: "perp_time",
-			Value: bson.D{
-				{
-					"$gte",
-					perpBeginTime.Seconds(),
-				},
-				{
-					"$lte",
-					perpEndTime.Seconds(),
-				},
-			},
+			Key:   "$text",
+			Value: bson.D{{Key: "$search", Value: q}},
 		})
 	}
 
-	if totalEndTime != 0 {
-		// 如果总结束时间不为0，将总时间添加到过滤器中
+	// 如果饮食习惯不为空，将饮食习惯添加到过滤器中
+	if dietary != "" {
+		cond, err := service.Recipe().Search().GetDietaryFilter(dietary)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, cond)
+	}
+
+	// 获取烹饪时间、准备时间和总时间的时间段，并将其添加到过滤器中
+	for _, t := range []struct{ field, value string }{
+		{"cook_time", cookTimeString},
+		{"perp_time", perpTimeString},
+		{"total_time", totalTimeString},
+	} {
+		beginTime, endTime := service.Recipe().Info().GetTimeDuration(t.value)
+		// 如果结束时间为0，不过滤这个时间
+		if endTime == 0 {
+			continue
+		}
 		filter = append(filter, bson.E{
-			Key: "total_time",
+			Key: t.field,
 			Value: bson.D{
-				{
-					"$gte",
-					totalBeginTime.Seconds(),
-				},
-				{
-					"$lte",
-					totalEndTime.Seconds(),
-				},
+				{Key: "$gte", Value: beginTime.Seconds()},
+				{Key: "$lte", Value: endTime.Seconds()},
 			},
 		})
 	}
 
+	for _, field := range recipe.NutritionFields {
+		// 对于每一个营养成分，获取其范围，例如calories=200-600、protein_min=30
+		cond, err := service.Recipe().Info().GetNutritionRange(
+			c.Query(field),
+			c.Query(field+"_min"),
+			c.Query(field+"_max"))
+		// 如果范围无效，返回错误
+		if err != nil {
+			return nil, fmt.Errorf(`invalid param "%s"`, field)
+		}
+		// 如果范围不为空，将其添加到过滤器中
+		if len(cond) > 0 {
+			filter = append(filter, bson.E{Key: field, Value: cond})
+		}
+	}
+
 	for _, ingredient := range ingredients {
 		// 对于食材列表中的每一个食材，将其添加到过滤器中
 		reg := bson.E{
@@ -197,18 +286,224 @@ func (a *Api) Search(c *gin.Context) {
 		filter = append(filter, reg)
 	}
 
-	// 打印过滤器的内容
-	g.Logger.Debugf("%v", filter)
+	// 获取排除食材和过敏原的过滤条件，并将其添加到过滤器中
+	exclude, err := service.Recipe().Search().GetExcludeFilter(excludeIngredients, allergens)
+	if err != nil {
+		return nil, err
+	}
+	if exclude.Key != "" {
+		filter = append(filter, exclude)
+	}
 
-	// 创建一个查找选项，并设置限制数和跳过的数量
-	option := &options.FindOptions{}
-	option.SetLimit(limit)
-	option.SetSkip(limit * (page - 1))
-	// 在集合中查找匹配的文档
-	cur, err := collection.Find(c, filter, option)
+	return filter, nil
+}
+
+// Detail 获取菜谱详情
+func (a *Api) Detail(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	// 从请求中获取份数，为空时不缩放
+	servings := cast.ToInt64(c.Query("servings"))
+	// 从请求中获取单位制，为空时使用用户默认的单位制
+	units := c.Query("units")
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果份数无效，返回错误
+	if servings < 0 || servings > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "servings"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果单位制无效，返回错误
+	if units != "" && !unit.IsValid(units) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "units"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "recipe not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 获取当前用户对这个菜谱的收藏
+	userCollection, err := service.User().Collect().GetRecipeCollection(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 没有指定单位制时，使用用户默认的单位制
+	if units == "" {
+		units, err = service.User().User().GetUnits(c, userId)
+		if err != nil {
+			switch err.Error() {
+			case "internal err":
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code": http.StatusInternalServerError,
+					"msg":  "internal err",
+					"ok":   false,
+				})
+			}
+
+			return
+		}
+	}
+
+	// 按份数缩放菜谱，并解析食材
+	parsed := service.Recipe().Info().ScaleRecipe(recipe, servings)
+	// 转换到指定的单位制
+	parsed = service.Recipe().Info().ConvertRecipe(recipe, parsed, units)
+
+	// 创建一个菜谱详情的对象
+	detail := &model.RecipeDetail{
+		Recipe:            recipe,
+		ParsedIngredients: parsed,
+		CollectCount:      recipe.CollectCount, // 与按热度排序使用同一个收藏次数
+	}
+	// 推导上传的图片的缩略图
+	for _, u := range recipe.Images {
+		if thumbnails := service.Recipe().Image().ThumbnailUrls(u); thumbnails != nil {
+			if detail.Thumbnails == nil {
+				detail.Thumbnails = make(map[string]map[int]string)
+			}
+			detail.Thumbnails[u] = thumbnails
+		}
+	}
+	// 如果用户收藏了这个菜谱，设置收藏的ID
+	if userCollection != nil {
+		detail.IsCollected = true
+		detail.CollectionId = userCollection.Id
+	}
+
+	// 返回成功响应，包括菜谱的详情
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get recipe detail successfully",
+		"ok":   true,
+		"data": detail,
+	})
+}
+
+// Match 根据用户已有的食材匹配菜谱
+func (a *Api) Match(c *gin.Context) {
+	// 从表单中获取已有的食材、饮食习惯、过敏原、最低覆盖率和限制数
+	ingredients := c.PostFormArray("ingredients")
+	dietary := c.PostForm("dietary")
+	allergens := c.PostFormArray("allergens")
+	minCoverage := cast.ToFloat64(c.PostForm("min_coverage"))
+	limit := cast.ToInt64(c.PostForm("limit"))
+
+	// 去掉空的食材
+	var pantry []string
+	for _, ingredient := range ingredients {
+		if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+			pantry = append(pantry, ingredient)
+		}
+	}
+
+	// 如果已有的食材为空，返回错误
+	if len(pantry) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "ingredients cannot be null",
+			"ok":   false,
+		})
+		return
+	}
+	// 如果最低覆盖率不在0到1之间，返回错误
+	if minCoverage < 0 || minCoverage > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "min_coverage"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果限制数不在1到50之间，返回错误
+	if limit <= 0 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 定义一个过滤器，排除其他用户的私有菜谱
+	filter := bson.D{service.Recipe().Search().GetVisibilityFilter(c.GetInt64("id"))}
+
+	// 如果饮食习惯不为空，将饮食习惯添加到过滤器中
+	if dietary != "" {
+		cond, err := service.Recipe().Search().GetDietaryFilter(dietary)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+			return
+		}
+		filter = append(filter, cond)
+	}
+
+	// 获取排除过敏原的过滤条件，并将其添加到过滤器中
+	exclude, err := service.Recipe().Search().GetExcludeFilter(nil, allergens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+	if exclude.Key != "" {
+		filter = append(filter, exclude)
+	}
+
+	// 根据已有的食材匹配菜谱
+	results, err := service.Recipe().Search().MatchRecipes(c, pantry, filter, minCoverage, limit)
 	if err != nil {
-		// 如果查找过程中出现错误，记录错误日志并返回内部错误
-		g.Logger.Errorf("find [recipe] document failed, err: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code": http.StatusInternalServerError,
 			"msg":  "internal err",
@@ -216,27 +511,271 @@ func (a *Api) Search(c *gin.Context) {
 		})
 		return
 	}
-	// 在函数返回后关闭游标
-	defer func(cur *mongo.Cursor, ctx context.Context) {
-		err := cur.Close(ctx)
+
+	// 返回成功响应，包括匹配结果的列表
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "match recipe successfully",
+		"ok":   true,
+		"data": results,
+	})
+}
+
+// PlanDay 根据热量目标、三大营养素的热量比例和饮食习惯生成一日菜单
+func (a *Api) PlanDay(c *gin.Context) {
+	// 从表单中获取热量目标、蛋白质、脂肪和碳水化合物的热量比例、饮食习惯、过敏原和餐次
+	calories := cast.ToFloat64(c.PostForm("calories"))
+	proteinRatio := cast.ToFloat64(c.DefaultPostForm("protein_ratio", "0.3"))
+	fatRatio := cast.ToFloat64(c.DefaultPostForm("fat_ratio", "0.3"))
+	carbohydrateRatio := cast.ToFloat64(c.DefaultPostForm("carbohydrate_ratio", "0.4"))
+	dietary := c.PostForm("dietary")
+	allergens := c.PostFormArray("allergens")
+	var meals []string
+	for _, v := range c.PostFormArray("meals") {
+		for _, meal := range strings.Split(v, ",") {
+			if meal = strings.TrimSpace(meal); meal != "" {
+				meals = append(meals, meal)
+			}
+		}
+	}
+
+	// 如果热量目标无效，返回错误，NaN和任何数值比较都为false，需要单独检查
+	if math.IsNaN(calories) || calories <= 0 || calories > 10000 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "calories"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果热量比例无效，返回错误
+	for _, ratio := range []float64{proteinRatio, fatRatio, carbohydrateRatio} {
+		if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  "invalid ratio",
+				"ok":   false,
+			})
+			return
+		}
+	}
+	if sum := proteinRatio + fatRatio + carbohydrateRatio; sum < 0.99 || sum > 1.01 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "the sum of ratios must be 1",
+			"ok":   false,
+		})
+		return
+	}
+	// 餐次为空时生成早餐、午餐、晚餐和加餐，否则检查餐次是否有效
+	if len(meals) == 0 {
+		for _, meal := range recipe.MenuMeals {
+			meals = append(meals, meal.Name)
+		}
+	}
+	for _, meal := range meals {
+		if !service.Recipe().Menu().CheckMeal(meal) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  fmt.Sprintf("invalid meal %q", meal),
+				"ok":   false,
+			})
+			return
+		}
+	}
+
+	// 定义一个过滤器，排除其他用户的私有菜谱
+	filter := bson.D{service.Recipe().Search().GetVisibilityFilter(c.GetInt64("id"))}
+
+	// 如果饮食习惯不为空，将饮食习惯添加到过滤器中
+	if dietary != "" {
+		cond, err := service.Recipe().Search().GetDietaryFilter(dietary)
 		if err != nil {
-			g.Logger.Errorf("close [recipe] document failed, err: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+			return
+		}
+		filter = append(filter, cond)
+	}
+
+	// 获取排除过敏原的过滤条件，并将其添加到过滤器中
+	exclude, err := service.Recipe().Search().GetExcludeFilter(nil, allergens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+	if exclude.Key != "" {
+		filter = append(filter, exclude)
+	}
+
+	// 生成一日菜单
+	target := service.Recipe().Menu().GetTarget(calories, proteinRatio, fatRatio, carbohydrateRatio)
+	menu, err := service.Recipe().Menu().PlanDay(c, filter, meals, target)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 返回成功响应，包括一日菜单
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "plan day successfully",
+		"ok":   true,
+		"data": menu,
+	})
+}
+
+// Similar 获取和给定的菜谱相似的菜谱
+func (a *Api) Similar(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	// 从请求中获取限制数，默认为10
+	limit := cast.ToInt(c.DefaultQuery("limit", "10"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果限制数不在1到50之间，返回错误
+	if limit <= 0 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "recipe not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
 		}
-	}(cur, c)
 
-	// 创建一个菜谱的列表
-	var results []*model.Recipe
+		return
+	}
 
-	// 遍历游标中的每一个文档
-	for cur.Next(c) {
-		// 创建一个菜谱的对象
-		var elem model.Recipe
+	// 查找相似的菜谱，排除其他用户的私有菜谱
+	filter := bson.D{service.Recipe().Search().GetVisibilityFilter(userId)}
+	results, err := service.Recipe().Search().SimilarRecipes(c, recipe, filter, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+		return
+	}
+
+	// 返回成功响应，包括相似的菜谱
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get similar recipes successfully",
+		"ok":   true,
+		"data": results,
+	})
+}
 
-		// 将文档解码为菜谱的对象
-		err := cur.Decode(&elem)
+// Recommended 根据用户收藏的菜谱推荐菜谱，没有收藏的用户返回热门的菜谱
+func (a *Api) Recommended(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从请求中获取限制数和页数，默认为20和1
+	limit := cast.ToInt(c.DefaultQuery("limit", "20"))
+	page := cast.ToInt(c.DefaultQuery("page", "1"))
+
+	// 如果限制数不在1到50之间，返回错误
+	if limit <= 0 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果页数小于等于0，或者超过候选菜谱的数量，返回错误
+	if page <= 0 || page > (recipe.RecommendCandidates+limit-1)/limit {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "page"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户最近收藏的菜谱
+	recipeIds, err := service.User().Collect().GetUserRecipeIds(c, userId, 200)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+		return
+	}
+	recipes, err := service.Recipe().Info().GetVisibleRecipes(c, userId, recipeIds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+		return
+	}
+	collected := make([]*model.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		collected = append(collected, recipe)
+	}
+
+	// 定义一个过滤器，排除其他用户的私有菜谱
+	filter := bson.D{service.Recipe().Search().GetVisibilityFilter(userId)}
+
+	// 根据收藏的菜谱推荐菜谱
+	var results []*model.RecipeScore
+	personalized := len(collected) > 0
+	if personalized {
+		results, err = service.Recipe().Search().RecommendRecipes(c, collected, filter, limit, page)
 		if err != nil {
-			// 如果解码过程中出现错误，记录错误日志并返回内部错误
-			g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"code": http.StatusInternalServerError,
 				"msg":  "internal err",
@@ -244,16 +783,180 @@ func (a *Api) Search(c *gin.Context) {
 			})
 			return
 		}
+		// 没有和口味画像匹配的菜谱时，返回没有收藏过的热门菜谱
+		if len(results) == 0 && page == 1 {
+			personalized = false
+			filter = append(filter, bson.E{Key: "recipe_id", Value: bson.D{{Key: "$nin", Value: recipeIds}}})
+		}
+	}
 
-		// 将菜谱添加到菜谱的列表中
-		results = append(results, &elem)
+	// 返回热门的菜谱
+	if !personalized {
+		results, err = service.Recipe().Search().PopularRecipes(c, filter, limit, page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+			return
+		}
 	}
 
-	// 返回成功响应，包括菜谱的列表
+	// 返回成功响应，包括推荐的菜谱和是否是个性化的推荐
+	c.JSON(http.StatusOK, gin.H{
+		"code":         http.StatusOK,
+		"msg":          "get recommended recipes successfully",
+		"ok":           true,
+		"data":         results,
+		"personalized": personalized,
+	})
+}
+
+// Categories 获取公开菜谱的所有分类和每个分类的菜谱数量
+func (a *Api) Categories(c *gin.Context) {
+	a.terms(c, recipe.TermFields["categories"])
+}
+
+// Keywords 获取公开菜谱的所有关键词和每个关键词的菜谱数量
+func (a *Api) Keywords(c *gin.Context) {
+	a.terms(c, recipe.TermFields["keywords"])
+}
+
+// terms 获取字段的所有值和菜谱数量，可以使用limit限制返回的数量
+func (a *Api) terms(c *gin.Context, field string) {
+	// 从请求中获取限制数，为空时返回所有的值
+	limit := cast.ToInt(c.Query("limit"))
+	if limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取字段的统计结果
+	terms, err := service.Recipe().Search().GetTerms(c, field)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		}
+		return
+	}
+	if limit > 0 && limit < len(terms) {
+		terms = terms[:limit]
+	}
+
+	// 返回成功的响应，包括字段的值和菜谱数量
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
-		"msg":  "get recipe successfully",
+		"msg":  "get terms successfully",
 		"ok":   true,
-		"data": results,
+		"data": terms,
+	})
+}
+
+// Substitutions 为菜谱中不符合饮食习惯或者含有过敏原的食材提供替换建议，过敏原包括请求中的和用户设置的
+func (a *Api) Substitutions(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID，并将其转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	// 从请求中获取饮食习惯和过敏原
+	diet := c.Query("diet")
+	allergenList := c.QueryArray("allergens")
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果饮食习惯无效，返回错误
+	if diet != "" && !recipe.Diets[diet] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "diet"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果过敏原无效，返回错误
+	for _, allergen := range allergenList {
+		if _, ok := recipe.Allergens[allergen]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  fmt.Sprintf(`invalid allergen "%s"`, allergen),
+				"ok":   false,
+			})
+			return
+		}
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	r, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "recipe not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 合并用户设置的过敏原
+	userAllergens, err := service.User().User().GetAllergens(c, userId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+	allergens := make([]string, 0, len(allergenList)+len(userAllergens))
+	for _, allergen := range append(allergenList, userAllergens...) {
+		if !slices.Contains(allergens, allergen) {
+			allergens = append(allergens, allergen)
+		}
+	}
+
+	// 获取食材的替换建议
+	substitutions := service.Recipe().Substitution().GetSubstitutions(r, diet, allergens)
+
+	// 返回成功的响应，包括需要替换的食材和替换建议
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get substitutions successfully",
+		"ok":   true,
+		"data": gin.H{
+			"recipe_id":     recipeId,
+			"diet":          diet,
+			"allergens":     allergens,
+			"substitutions": substitutions,
+		},
 	})
 }
@@ -0,0 +1,264 @@
+package recipe
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"net/http"
+)
+
+// ReviewApi 定义一个菜谱评价API的结构体
+type ReviewApi struct{}
+
+// GetList 分页获取菜谱的评价，包括平均星级和评价数量
+func (a *ReviewApi) GetList(c *gin.Context) {
+	// 从路径中获取菜谱ID，从请求中获取限制数和页数，并将它们转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	limit := cast.ToInt(c.Query("limit"))
+	page := cast.ToInt(c.Query("page"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果限制数小于等于0，返回错误
+	if limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果页数小于等于0，返回错误
+	if page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "page"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, c.GetInt64("id"), recipeId)
+	if err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 获取菜谱的评价数量，并计算页数
+	cnt, err := service.Recipe().Review().GetReviewCount(c, recipeId)
+	if err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+	pageCount := int(cnt) / limit
+	if int(cnt)%limit > 0 {
+		pageCount = pageCount + 1
+	}
+
+	// 如果页数大于最大页数，返回错误
+	if page > pageCount && cnt > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  fmt.Sprintf("the maximum number of pages is %d", pageCount),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 分页获取菜谱的评价
+	reviews, err := service.Recipe().Review().GetReviewsWithLimit(c, recipeId, limit, page)
+	if err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括评价的列表、平均星级和评价数量
+	c.JSON(http.StatusOK, gin.H{
+		"code":         http.StatusOK,
+		"msg":          "get reviews successfully",
+		"ok":           true,
+		"data":         reviews,
+		"total":        cnt,
+		"page_count":   pageCount,
+		"rating_avg":   recipe.RatingAvg,
+		"rating_count": recipe.RatingCount,
+	})
+}
+
+// Create 创建用户对菜谱的评价
+func (a *ReviewApi) Create(c *gin.Context) {
+	// 从上下文中获取用户ID，从路径中获取菜谱ID
+	userId := c.GetInt64("id")
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 从表单中获取星级和内容，并检查评价
+	review := &model.RecipeReview{
+		RecipeId: recipeId,
+		UserId:   userId,
+		Rating:   cast.ToInt32(c.PostForm("rating")),
+		Content:  c.PostForm("content"),
+	}
+	if err := service.Recipe().Review().ValidateReview(review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查菜谱是否存在，其他用户的私有菜谱视为不存在
+	if _, err := service.Recipe().Info().GetVisibleRecipe(c, userId, recipeId); err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 创建评价
+	if err := service.Recipe().Review().CreateReview(c, review); err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括创建的评价
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "create review successfully",
+		"ok":   true,
+		"data": review,
+	})
+}
+
+// Update 更新用户对菜谱的评价，只更新提交的字段
+func (a *ReviewApi) Update(c *gin.Context) {
+	// 从上下文中获取用户ID，从路径中获取菜谱ID
+	userId := c.GetInt64("id")
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户对菜谱的评价
+	review, err := service.Recipe().Review().GetReview(c, recipeId, userId)
+	if err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 使用提交的字段更新评价，并检查评价
+	if rating, ok := c.GetPostForm("rating"); ok {
+		review.Rating = cast.ToInt32(rating)
+	}
+	if content, ok := c.GetPostForm("content"); ok {
+		review.Content = content
+	}
+	if err = service.Recipe().Review().ValidateReview(review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 更新评价
+	if err = service.Recipe().Review().UpdateReview(c, review); err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括更新后的评价
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "update review successfully",
+		"ok":   true,
+		"data": review,
+	})
+}
+
+// Delete 删除用户对菜谱的评价
+func (a *ReviewApi) Delete(c *gin.Context) {
+	// 从上下文中获取用户ID，从路径中获取菜谱ID
+	userId := c.GetInt64("id")
+	recipeId := cast.ToInt64(c.Param("id"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户对菜谱的评价
+	review, err := service.Recipe().Review().GetReview(c, recipeId, userId)
+	if err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 删除评价
+	if err = service.Recipe().Review().DeleteReview(c, review); err != nil {
+		responseReviewErr(c, err)
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "delete review successfully",
+		"ok":   true,
+	})
+}
+
+// responseReviewErr 根据菜谱评价的错误返回对应的响应
+func responseReviewErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "recipe not found", "review not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "review already exist":
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
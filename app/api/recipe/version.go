@@ -0,0 +1,269 @@
+package recipe
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/service"
+	"net/http"
+)
+
+// VersionApi 定义一个菜谱版本历史API的结构体
+type VersionApi struct{}
+
+// GetList 分页获取菜谱的版本，按版本号从新到旧排序
+func (a *VersionApi) GetList(c *gin.Context) {
+	// 从路径中获取菜谱ID，从请求中获取限制数和页数，并将它们转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	limit := cast.ToInt(c.Query("limit"))
+	page := cast.ToInt(c.Query("page"))
+
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果限制数小于等于0，返回错误
+	if limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "limit"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果页数小于等于0，返回错误
+	if page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "page"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, c.GetInt64("id"), recipeId)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 获取菜谱的版本数量，并计算页数
+	cnt, err := service.Recipe().Version().GetVersionCount(c, recipeId)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+	pageCount := int(cnt) / limit
+	if int(cnt)%limit > 0 {
+		pageCount = pageCount + 1
+	}
+
+	// 如果页数大于最大页数，返回错误
+	if page > pageCount && cnt > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  fmt.Sprintf("the maximum number of pages is %d", pageCount),
+			"ok":   false,
+		})
+		return
+	}
+
+	// 分页获取菜谱的版本
+	versions, err := service.Recipe().Version().GetVersionsWithLimit(c, recipeId, limit, page)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括版本的列表和当前的版本号
+	c.JSON(http.StatusOK, gin.H{
+		"code":       http.StatusOK,
+		"msg":        "get versions successfully",
+		"ok":         true,
+		"data":       versions,
+		"total":      cnt,
+		"page_count": pageCount,
+		"version":    recipe.Version,
+		"parent_id":  recipe.ParentId,
+	})
+}
+
+// Detail 获取菜谱的一个版本，包括这个版本的菜谱内容
+func (a *VersionApi) Detail(c *gin.Context) {
+	// 从路径中获取菜谱ID和版本号，并将它们转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	version := cast.ToInt64(c.Param("version"))
+
+	if !checkVersionParams(c, recipeId, version) {
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	_, err := service.Recipe().Info().GetVisibleRecipe(c, c.GetInt64("id"), recipeId)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 获取菜谱的版本
+	res, err := service.Recipe().Version().GetVersion(c, recipeId, version)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括版本的内容
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get version successfully",
+		"ok":   true,
+		"data": res,
+	})
+}
+
+// Diff 比较菜谱的两个版本，to为空时和菜谱当前的内容比较
+func (a *VersionApi) Diff(c *gin.Context) {
+	// 从路径中获取菜谱ID和版本号，从请求中获取比较的版本号，并将它们转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	version := cast.ToInt64(c.Param("version"))
+	to := cast.ToInt64(c.Query("to"))
+
+	if !checkVersionParams(c, recipeId, version) {
+		return
+	}
+	// 如果比较的版本号小于0，返回错误
+	if to < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "to"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := service.Recipe().Info().GetVisibleRecipe(c, c.GetInt64("id"), recipeId)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 获取需要比较的两个版本，没有指定比较的版本时使用菜谱当前的内容，不依赖当前版本的快照
+	from, err := service.Recipe().Version().GetVersion(c, recipeId, version)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+	target := service.Recipe().Version().CurrentVersion(recipe)
+	if to != 0 {
+		target, err = service.Recipe().Version().GetVersion(c, recipeId, to)
+		if err != nil {
+			responseVersionErr(c, err)
+			return
+		}
+	}
+
+	// 返回成功响应，包括两个版本之间的变化
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "diff versions successfully",
+		"ok":   true,
+		"data": service.Recipe().Version().DiffVersions(from, target),
+	})
+}
+
+// Revert 将用户自己的菜谱恢复到指定版本的内容，恢复后产生一个新的版本
+func (a *VersionApi) Revert(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取菜谱ID和版本号，并将它们转换为整数
+	recipeId := cast.ToInt64(c.Param("id"))
+	version := cast.ToInt64(c.Param("version"))
+
+	if !checkVersionParams(c, recipeId, version) {
+		return
+	}
+
+	// 获取用户自己的菜谱
+	recipe, err := service.Recipe().Manage().GetOwnRecipe(c, userId, recipeId)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 获取需要恢复的版本
+	res, err := service.Recipe().Version().GetVersion(c, recipeId, version)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 在数据库中恢复菜谱
+	err = service.Recipe().Manage().RevertRecipe(c, recipe, res)
+	if err != nil {
+		responseVersionErr(c, err)
+		return
+	}
+
+	// 返回成功的响应，包括恢复后的菜谱
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "revert recipe successfully",
+		"ok":   true,
+		"data": recipe,
+	})
+}
+
+// checkVersionParams 检查路径中的菜谱ID和版本号，无效时返回错误的响应和false
+func checkVersionParams(c *gin.Context, recipeId, version int64) bool {
+	// 如果菜谱ID小于等于0，返回错误
+	if recipeId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return false
+	}
+	// 如果版本号小于等于0，返回错误
+	if version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "version"`,
+			"ok":   false,
+		})
+		return false
+	}
+
+	return true
+}
+
+// responseVersionErr 根据处理菜谱版本时出现的错误返回对应的响应
+func responseVersionErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "recipe not found", "version not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	case "permission denied":
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": http.StatusForbidden,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
@@ -276,7 +276,25 @@ func (a *CollectApi) Create(c *gin.Context) {
 	}
 
 	// 在数据库中创建收藏
-	service.User().Collect().CreateCollection(c, userCollection)
+	err = service.User().Collect().CreateCollection(c, userCollection)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 收藏创建成功后，如果收藏的是菜谱，增加菜谱的收藏次数
+	if collectType == 2 {
+		_ = service.Recipe().Search().IncrCollectCount(c, userCollection.RecipeId, 1)
+	}
+
 	// 返回成功的响应
 	c.JSON(http.StatusOK, gin.H{
 		"code": http.StatusOK,
@@ -302,7 +320,7 @@ func (a *CollectApi) Delete(c *gin.Context) {
 	}
 
 	// 检查收藏ID是否存在
-	err := service.User().Collect().CheckCollectionIdIsExist(c, id, userId)
+	userCollection, err := service.User().Collect().CheckCollectionIdIsExist(c, id, userId)
 	if err != nil {
 		switch err.Error() {
 		case "internal err":
@@ -333,6 +351,13 @@ func (a *CollectApi) Delete(c *gin.Context) {
 				"ok":   false,
 			})
 		}
+
+		return
+	}
+
+	// 如果删除的是菜谱的收藏，减少菜谱的收藏次数
+	if userCollection.CollectType == 2 {
+		_ = service.Recipe().Search().IncrCollectCount(c, userCollection.RecipeId, -1)
 	}
 
 	// 返回成功的响应
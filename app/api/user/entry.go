@@ -15,3 +15,24 @@ var insCollect = CollectApi{}
 func (g *Group) Collect() *CollectApi {
 	return &insCollect
 }
+
+// insPreference 创建一个偏好设置API的实例
+var insPreference = PreferenceApi{}
+
+func (g *Group) Preference() *PreferenceApi {
+	return &insPreference
+}
+
+// insPlan 创建一个膳食计划API的实例
+var insPlan = PlanApi{}
+
+func (g *Group) Plan() *PlanApi {
+	return &insPlan
+}
+
+// insShopping 创建一个购物清单API的实例
+var insShopping = ShoppingApi{}
+
+func (g *Group) Shopping() *ShoppingApi {
+	return &insShopping
+}
@@ -0,0 +1,282 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"net/http"
+)
+
+// PlanApi 定义一个膳食计划API的结构体
+type PlanApi struct{}
+
+// Week 获取一周的膳食计划，包括每一天营养成分的合计
+func (a *PlanApi) Week(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 从请求中获取日期，返回这个日期所在的一周，为空时返回本周
+	date, err := service.User().Plan().ParseDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "date"`,
+			"ok":   false,
+		})
+		return
+	}
+	start := service.User().Plan().WeekStart(date)
+
+	// 获取这一周的膳食计划
+	mealPlans, err := service.User().Plan().GetPlans(c, userId, start, start.AddDate(0, 0, 6))
+	if err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 获取膳食计划中的菜谱
+	recipeIds := make([]int64, 0, len(mealPlans))
+	for _, mealPlan := range mealPlans {
+		recipeIds = append(recipeIds, mealPlan.RecipeId)
+	}
+	recipes, err := service.Recipe().Info().GetVisibleRecipes(c, userId, recipeIds)
+	if err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括每一天的膳食计划
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get meal plan successfully",
+		"ok":   true,
+		"data": gin.H{
+			"week_start": start.Format("2006-01-02"),
+			"days":       service.User().Plan().BuildWeek(start, mealPlans, recipes),
+		},
+	})
+}
+
+// Create 在膳食计划中安排一个菜谱
+func (a *PlanApi) Create(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 从表单中获取日期、餐次、菜谱ID和份数
+	mealPlan := &model.MealPlan{
+		UserId:   userId,
+		PlanDate: c.PostForm("date"),
+		Meal:     c.PostForm("meal"),
+		RecipeId: cast.ToInt64(c.PostForm("recipe_id")),
+		Servings: cast.ToInt64(c.PostForm("servings")),
+	}
+
+	// 检查膳食计划和菜谱
+	if !checkPlan(c, mealPlan) {
+		return
+	}
+
+	// 在数据库中创建膳食计划
+	if err := service.User().Plan().CreatePlan(c, mealPlan); err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括创建的膳食计划
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "create meal plan successfully",
+		"ok":   true,
+		"data": mealPlan,
+	})
+}
+
+// Update 更新膳食计划中的一项，只更新提交的字段
+func (a *PlanApi) Update(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取膳食计划的ID，并将其转换为整数
+	id := cast.ToInt64(c.Param("id"))
+
+	// 如果ID小于等于0，返回错误
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户的膳食计划
+	mealPlan, err := service.User().Plan().GetPlan(c, id, userId)
+	if err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 使用提交的字段更新膳食计划
+	if date, ok := c.GetPostForm("date"); ok {
+		mealPlan.PlanDate = date
+	}
+	if meal, ok := c.GetPostForm("meal"); ok {
+		mealPlan.Meal = meal
+	}
+	if recipeId, ok := c.GetPostForm("recipe_id"); ok {
+		mealPlan.RecipeId = cast.ToInt64(recipeId)
+	}
+	if servings, ok := c.GetPostForm("servings"); ok {
+		mealPlan.Servings = cast.ToInt64(servings)
+	}
+
+	// 检查膳食计划和菜谱
+	if !checkPlan(c, mealPlan) {
+		return
+	}
+
+	// 在数据库中更新膳食计划
+	if err = service.User().Plan().UpdatePlan(c, mealPlan); err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括更新后的膳食计划
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "update meal plan successfully",
+		"ok":   true,
+		"data": mealPlan,
+	})
+}
+
+// Delete 删除膳食计划中的一项
+func (a *PlanApi) Delete(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取膳食计划的ID，并将其转换为整数
+	id := cast.ToInt64(c.Param("id"))
+
+	// 如果ID小于等于0，返回错误
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查膳食计划是否存在
+	if _, err := service.User().Plan().GetPlan(c, id, userId); err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 在数据库中删除膳食计划
+	if err := service.User().Plan().DeletePlan(c, id, userId); err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "delete meal plan successfully",
+		"ok":   true,
+	})
+}
+
+// Copy 将一周的膳食计划复制到另一周，默认将上一周复制到本周，不清空目标周时跳过目标周已有的相同膳食计划
+func (a *PlanApi) Copy(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从表单中获取是否清空目标周的膳食计划
+	replace := cast.ToBool(c.PostForm("replace"))
+
+	// 从表单中获取目标周的日期，为空时为本周
+	to, err := service.User().Plan().ParseDate(c.PostForm("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "to"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 从表单中获取源周的日期，为空时为目标周的上一周
+	from := to.AddDate(0, 0, -7)
+	if c.PostForm("from") != "" {
+		from, err = service.User().Plan().ParseDate(c.PostForm("from"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  `invalid param "from"`,
+				"ok":   false,
+			})
+			return
+		}
+	}
+
+	// 复制膳食计划
+	cnt, err := service.User().Plan().CopyWeek(c, userId, from, to, replace)
+	if err != nil {
+		responsePlanErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括复制的数量
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "copy meal plan successfully",
+		"ok":   true,
+		"data": gin.H{
+			"copied": cnt,
+		},
+	})
+}
+
+// checkPlan 检查膳食计划是否有效，以及菜谱是否对用户可见，无效时返回错误响应
+func checkPlan(c *gin.Context, mealPlan *model.MealPlan) bool {
+	// 检查膳食计划的字段
+	if err := service.User().Plan().ValidatePlan(mealPlan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+		return false
+	}
+
+	// 检查菜谱是否存在，其他用户的私有菜谱视为不存在
+	if _, err := service.Recipe().Info().GetVisibleRecipe(c, mealPlan.UserId, mealPlan.RecipeId); err != nil {
+		responsePlanErr(c, err)
+		return false
+	}
+
+	return true
+}
+
+// responsePlanErr 根据膳食计划的错误返回对应的响应
+func responsePlanErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "meal plan not found", "recipe not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
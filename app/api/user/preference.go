@@ -0,0 +1,132 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"main/app/internal/service"
+	"main/app/internal/service/recipe"
+	"main/app/internal/service/user"
+	"main/utils/unit"
+	"net/http"
+	"slices"
+)
+
+// PreferenceApi 定义一个用户偏好设置API的结构体
+type PreferenceApi struct{}
+
+// Get 获取用户的偏好设置
+func (a *PreferenceApi) Get(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 获取用户的信息
+	userSubject, err := service.User().User().GetUser(c, userId)
+	if err != nil {
+		switch err.Error() {
+		case "internal err":
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": http.StatusInternalServerError,
+				"msg":  "internal err",
+				"ok":   false,
+			})
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": http.StatusNotFound,
+				"msg":  err.Error(),
+				"ok":   false,
+			})
+		}
+
+		return
+	}
+
+	// 返回成功响应，包括用户的偏好设置
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get preference successfully",
+		"ok":   true,
+		"data": gin.H{
+			"units":     userSubject.Units,
+			"allergens": user.SplitAllergens(userSubject.Allergens),
+		},
+	})
+}
+
+// Update 更新用户的偏好设置，只更新请求中提交的字段
+func (a *PreferenceApi) Update(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从请求中获取默认的单位制，为空时表示不转换
+	units, hasUnits := c.GetPostForm("units")
+	// 从请求中获取过敏原，只有空值时表示清空
+	allergenList, hasAllergens := c.GetPostFormArray("allergens")
+
+	// 如果没有需要更新的字段，返回错误
+	if !hasUnits && !hasAllergens {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "preference cannot be null",
+			"ok":   false,
+		})
+		return
+	}
+	// 如果单位制无效，返回错误
+	if units != "" && !unit.IsValid(units) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "units"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果过敏原无效，返回错误，并去掉空值和重复的过敏原
+	allergens := make([]string, 0, len(allergenList))
+	for _, allergen := range allergenList {
+		if allergen == "" || slices.Contains(allergens, allergen) {
+			continue
+		}
+		if _, ok := recipe.Allergens[allergen]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  fmt.Sprintf(`invalid allergen "%s"`, allergen),
+				"ok":   false,
+			})
+			return
+		}
+		allergens = append(allergens, allergen)
+	}
+
+	// 更新用户默认的单位制
+	if hasUnits {
+		if err := service.User().User().UpdateUnits(c, userId, units); err != nil {
+			responsePreferenceErr(c, err)
+			return
+		}
+	}
+	// 更新用户的过敏原
+	if hasAllergens {
+		if err := service.User().User().UpdateAllergens(c, userId, allergens); err != nil {
+			responsePreferenceErr(c, err)
+			return
+		}
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "update preference successfully",
+		"ok":   true,
+	})
+}
+
+// responsePreferenceErr 根据更新偏好设置时出现的错误返回对应的响应
+func responsePreferenceErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"main/app/internal/service/user"
+	"main/utils/unit"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ShoppingApi 定义一个购物清单API的结构体
+type ShoppingApi struct{}
+
+// GetList 获取用户的购物清单列表
+func (a *ShoppingApi) GetList(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 获取用户的购物清单
+	shoppingLists, err := service.User().Shopping().GetLists(c, userId)
+	if err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括购物清单的列表
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get shopping lists successfully",
+		"ok":   true,
+		"data": shoppingLists,
+	})
+}
+
+// Create 根据菜谱或者膳食计划的日期范围生成购物清单
+func (a *ShoppingApi) Create(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+
+	// 从表单中获取名称、菜谱ID、份数、膳食计划的日期范围和单位制
+	name := strings.TrimSpace(c.PostForm("name"))
+	var recipeIds []int64
+	for _, v := range c.PostFormArray("recipe_ids") {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				recipeIds = append(recipeIds, cast.ToInt64(id))
+			}
+		}
+	}
+	servings := cast.ToInt64(c.PostForm("servings"))
+	from, to := c.PostForm("from"), c.PostForm("to")
+	units := c.PostForm("units")
+
+	// 如果名称太长，返回错误
+	if len([]rune(name)) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "name"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果菜谱ID和日期范围都为空，返回错误
+	if len(recipeIds) == 0 && from == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "recipe_ids or from cannot be null",
+			"ok":   false,
+		})
+		return
+	}
+	// 如果份数无效，返回错误
+	if servings < 0 || servings > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "servings"`,
+			"ok":   false,
+		})
+		return
+	}
+	// 如果单位制无效，返回错误
+	if units != "" && !unit.IsValid(units) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "units"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 定义生成购物清单的菜谱
+	var sources []*user.ShoppingSource
+
+	// 根据菜谱ID生成
+	if len(recipeIds) > 0 {
+		for _, id := range recipeIds {
+			if id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code": http.StatusBadRequest,
+					"msg":  `invalid param "recipe_ids"`,
+					"ok":   false,
+				})
+				return
+			}
+		}
+
+		// 获取菜谱，其他用户的私有菜谱视为不存在
+		recipes, err := service.Recipe().Info().GetVisibleRecipes(c, userId, recipeIds)
+		if err != nil {
+			responseShoppingErr(c, err)
+			return
+		}
+		for _, id := range recipeIds {
+			recipe, ok := recipes[id]
+			if !ok {
+				c.JSON(http.StatusNotFound, gin.H{
+					"code": http.StatusNotFound,
+					"msg":  fmt.Sprintf("recipe %d not found", id),
+					"ok":   false,
+				})
+				return
+			}
+			sources = append(sources, &user.ShoppingSource{Recipe: recipe, Servings: servings})
+		}
+	}
+
+	// 根据膳食计划的日期范围生成
+	if from != "" {
+		start, err := service.User().Plan().ParseDate(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": http.StatusBadRequest,
+				"msg":  `invalid param "from"`,
+				"ok":   false,
+			})
+			return
+		}
+		// 结束日期为空时和开始日期相同，最多31天
+		end := start
+		if to != "" {
+			end, err = service.User().Plan().ParseDate(to)
+			if err != nil || end.Before(start) || end.Sub(start) > 30*24*time.Hour {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code": http.StatusBadRequest,
+					"msg":  `invalid param "to"`,
+					"ok":   false,
+				})
+				return
+			}
+		}
+
+		// 获取日期范围内的膳食计划和菜谱
+		mealPlans, err := service.User().Plan().GetPlans(c, userId, start, end)
+		if err != nil {
+			responseShoppingErr(c, err)
+			return
+		}
+		planRecipeIds := make([]int64, 0, len(mealPlans))
+		for _, mealPlan := range mealPlans {
+			planRecipeIds = append(planRecipeIds, mealPlan.RecipeId)
+		}
+		recipes, err := service.Recipe().Info().GetVisibleRecipes(c, userId, planRecipeIds)
+		if err != nil {
+			responseShoppingErr(c, err)
+			return
+		}
+
+		// 使用膳食计划的份数，忽略已经不存在的菜谱
+		for _, mealPlan := range mealPlans {
+			if recipe, ok := recipes[mealPlan.RecipeId]; ok {
+				sources = append(sources, &user.ShoppingSource{Recipe: recipe, Servings: mealPlan.Servings})
+			}
+		}
+
+		// 默认使用日期范围作为名称
+		if name == "" {
+			name = start.Format(user.DateLayout)
+			if end.After(start) {
+				name += " ~ " + end.Format(user.DateLayout)
+			}
+		}
+	}
+
+	// 如果没有菜谱，返回错误
+	if len(sources) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  "no recipes to shop for",
+			"ok":   false,
+		})
+		return
+	}
+
+	// 没有指定单位制时，使用用户默认的单位制
+	if units == "" {
+		var err error
+		units, err = service.User().User().GetUnits(c, userId)
+		if err != nil {
+			responseShoppingErr(c, err)
+			return
+		}
+	}
+
+	// 默认使用当前日期作为名称
+	if name == "" {
+		name = time.Now().Format(user.DateLayout)
+	}
+
+	// 创建购物清单，合并相同的食材
+	shoppingList := &model.ShoppingList{
+		UserId: userId,
+		Name:   name,
+		Items:  service.User().Shopping().BuildItems(sources, units),
+	}
+	if err := service.User().Shopping().CreateList(c, shoppingList); err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括购物清单和清单中的每一项
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "create shopping list successfully",
+		"ok":   true,
+		"data": shoppingList,
+	})
+}
+
+// Detail 获取一个购物清单和清单中的每一项
+func (a *ShoppingApi) Detail(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取购物清单的ID，并将其转换为整数
+	id := cast.ToInt64(c.Param("id"))
+
+	// 如果ID小于等于0，返回错误
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 获取用户的购物清单
+	shoppingList, err := service.User().Shopping().GetList(c, id, userId)
+	if err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括购物清单和清单中的每一项
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "get shopping list successfully",
+		"ok":   true,
+		"data": shoppingList,
+	})
+}
+
+// CheckItem 将购物清单中的一项标记为已购买或者未购买
+func (a *ShoppingApi) CheckItem(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取购物清单和清单项的ID，并将它们转换为整数
+	id := cast.ToInt64(c.Param("id"))
+	itemId := cast.ToInt64(c.Param("item"))
+	// 从表单中获取是否已经购买
+	checked := cast.ToBool(c.DefaultPostForm("checked", "true"))
+
+	// 如果ID小于等于0，返回错误
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+	if itemId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "item"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查购物清单是否属于这个用户
+	if _, err := service.User().Shopping().GetList(c, id, userId); err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 更新清单项是否已经购买
+	item, err := service.User().Shopping().CheckItem(c, id, itemId, checked)
+	if err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 返回成功响应，包括更新后的清单项
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "update shopping item successfully",
+		"ok":   true,
+		"data": item,
+	})
+}
+
+// Delete 删除一个购物清单
+func (a *ShoppingApi) Delete(c *gin.Context) {
+	// 从上下文中获取用户ID
+	userId := c.GetInt64("id")
+	// 从路径中获取购物清单的ID，并将其转换为整数
+	id := cast.ToInt64(c.Param("id"))
+
+	// 如果ID小于等于0，返回错误
+	if id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  `invalid param "id"`,
+			"ok":   false,
+		})
+		return
+	}
+
+	// 检查购物清单是否属于这个用户
+	if _, err := service.User().Shopping().GetList(c, id, userId); err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 删除购物清单
+	if err := service.User().Shopping().DeleteList(c, id); err != nil {
+		responseShoppingErr(c, err)
+		return
+	}
+
+	// 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": http.StatusOK,
+		"msg":  "delete shopping list successfully",
+		"ok":   true,
+	})
+}
+
+// responseShoppingErr 根据购物清单的错误返回对应的响应
+func responseShoppingErr(c *gin.Context, err error) {
+	switch err.Error() {
+	case "internal err":
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": http.StatusInternalServerError,
+			"msg":  "internal err",
+			"ok":   false,
+		})
+	case "shopping list not found", "shopping item not found":
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": http.StatusNotFound,
+			"msg":  err.Error(),
+			"ok":   false,
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"main/app/internal/model"
+	"main/app/internal/service"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportOptions 定义导入菜谱的选项
+type ImportOptions struct {
+	Files  []string  // 需要导入的文件
+	Format string    // 文件格式(jsonl或csv)，为空时根据扩展名判断
+	DryRun bool      // 只检查文件，不写入数据库
+	Out    io.Writer // 输出报告的位置
+}
+
+// ImportRecipes 从JSONL和CSV文件中导入菜谱，返回是否全部成功
+func ImportRecipes(ctx context.Context, opts *ImportOptions) bool {
+	ok := true
+	for _, file := range opts.Files {
+		if !importFile(ctx, file, opts) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// importFile 导入一个文件，并输出导入的报告
+func importFile(ctx context.Context, file string, opts *ImportOptions) bool {
+	// 获取文件格式
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+		if format == "json" || format == "ndjson" {
+			format = "jsonl"
+		}
+	}
+
+	// 打开文件
+	f, err := os.Open(file)
+	if err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "%s: open file failed, err: %v\n", file, err)
+		return false
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	// 根据文件格式解析菜谱
+	var recipes []*model.Recipe
+	var errs []*model.RecipeImportError
+	switch format {
+	case "jsonl":
+		recipes, errs = service.Recipe().Import().ParseJSONL(f)
+	case "csv":
+		recipes, errs = service.Recipe().Import().ParseCSV(f)
+	default:
+		_, _ = fmt.Fprintf(opts.Out, "%s: unsupported format %q\n", file, format)
+		return false
+	}
+
+	// 输出无效的行
+	for _, e := range errs {
+		_, _ = fmt.Fprintf(opts.Out, "%s:%d: %s\n", file, e.Line, e.Msg)
+	}
+	_, _ = fmt.Fprintf(opts.Out, "%s: %d valid, %d invalid\n", file, len(recipes), len(errs))
+
+	// 如果只检查文件，不写入数据库
+	if opts.DryRun {
+		_, _ = fmt.Fprintf(opts.Out, "%s: dry run, nothing written\n", file)
+		return len(errs) == 0
+	}
+
+	// 按菜谱ID写入数据库
+	matched, modified, upserted, writeErrs, err := service.Recipe().Import().UpsertRecipes(ctx, recipes)
+	for _, e := range writeErrs {
+		_, _ = fmt.Fprintf(opts.Out, "%s: recipe_id %d: %s\n", file, e.RecipeId, e.Msg)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(opts.Out, "%s: import failed, err: %v\n", file, err)
+		return false
+	}
+	_, _ = fmt.Fprintf(opts.Out, "%s: %d inserted, %d updated, %d unchanged, %d failed\n",
+		file, upserted, modified, matched-modified, len(writeErrs))
+
+	return len(errs) == 0 && len(writeErrs) == 0
+}
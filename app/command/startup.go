@@ -0,0 +1,30 @@
+package command
+
+import (
+	"context"
+	g "main/app/global"
+	"main/app/internal/dao"
+	"main/app/internal/service"
+)
+
+// Migrate 迁移MySQL的表结构，并创建MongoDB集合的索引，失败时终止启动
+// 唯一索引和计数器是创建菜谱的前提，迁移失败时继续运行会产生重复的菜谱ID
+func Migrate() {
+	if err := dao.Migration(); err != nil {
+		g.Logger.Fatalf("migrate mysql failed, err: %v", err)
+	}
+	MigrateMongo()
+}
+
+// MigrateMongo 创建MongoDB集合的索引并初始化计数器，失败时终止，导入菜谱前也需要执行
+func MigrateMongo() {
+	if err := dao.MongoMigration(); err != nil {
+		g.Logger.Fatalf("migrate mongodb failed, err: %v", err)
+	}
+}
+
+// StartJobs 启动后台任务，ctx取消时停止
+func StartJobs(ctx context.Context) {
+	// 在后台定时刷新菜谱分类和关键词的统计缓存
+	service.Recipe().Search().RefreshTermsPeriodically(ctx)
+}
@@ -14,4 +14,6 @@ var (
 	MysqlDB *gorm.DB
 	MongoDB *mongo.Client
 	Rdb     *redis.Client
+
+	Substitution *config.Substitution
 )
@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"main/app/internal/dao/recipe"
 	"main/app/internal/dao/user"
 )
 
@@ -9,3 +10,9 @@ var insUser = user.Group{}
 func User() *user.Group {
 	return &insUser
 }
+
+var insRecipe = recipe.Group{}
+
+func Recipe() *recipe.Group {
+	return &insRecipe
+}
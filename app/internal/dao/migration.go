@@ -1,16 +1,170 @@
 package dao
 
 import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	g "main/app/global"
 	"main/app/internal/model"
+	"time"
 )
 
-// Migration 执行数据迁移
-func Migration() {
+// Migration 执行数据迁移，失败时返回错误
+func Migration() error {
 	// 自动迁移模式
 	err := g.MysqlDB.Set("gorm:table_options", "CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci").
-		AutoMigrate(&model.UserSubject{}, &model.UserCollection{})
+		AutoMigrate(&model.UserSubject{}, &model.UserCollection{}, &model.RecipeReview{}, &model.MealPlan{},
+			&model.ShoppingList{}, &model.ShoppingItem{})
 	if err != nil {
-		return
+		return fmt.Errorf("auto migrate mysql tables failed, err: %v", err)
 	}
+
+	return nil
+}
+
+// MongoMigration 创建MongoDB集合的索引，初始化计数器并补充字段的默认值，任何一步失败时返回错误
+func MongoMigration() error {
+	// 获取菜谱的集合
+	collection := g.MongoDB.Database("food").Collection("recipe")
+
+	// 创建菜谱的全文索引，名称的权重最高，其次是关键词、食材和描述
+	_, err := collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "keywords", Value: "text"},
+			{Key: "ingredients", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("recipe_text").
+			SetWeights(bson.D{
+				{Key: "name", Value: 10},
+				{Key: "keywords", Value: 5},
+				{Key: "ingredients", Value: 3},
+				{Key: "description", Value: 1},
+			}),
+	})
+	if err != nil {
+		return fmt.Errorf("create [recipe] text index failed, err: %v", err)
+	}
+
+	// 创建菜谱ID的唯一索引
+	_, err = collection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "recipe_id", Value: 1}},
+		Options: options.Index().SetName("recipe_id").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("create [recipe] recipe_id index failed, err: %v", err)
+	}
+
+	// 将菜谱ID的计数器初始化为当前最大的菜谱ID，新建的菜谱从计数器分配ID
+	last := &model.Recipe{}
+	err = collection.FindOne(context.TODO(), bson.D{},
+		options.FindOne().SetSort(bson.D{{Key: "recipe_id", Value: -1}})).
+		Decode(last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return fmt.Errorf("find [recipe] document failed, err: %v", err)
+	}
+	_, err = g.MongoDB.Database("food").Collection("counter").UpdateOne(context.TODO(),
+		bson.D{{Key: "_id", Value: "recipe_id"}},
+		bson.D{{Key: "$max", Value: bson.D{{Key: "seq", Value: last.RecipeId}}}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("update [counter] document failed, err: %v", err)
+	}
+
+	// 为菜谱补充收藏次数，第一次启动时按用户收藏表回填，保证按热度排序的结果与收藏记录一致
+	err = seedCollectCount(collection)
+	if err != nil {
+		return fmt.Errorf("update [recipe] collect_count failed, err: %v", err)
+	}
+
+	// 为没有评价统计的菜谱补充默认值，保证按评分排序和分页时的结果稳定
+	_, err = collection.UpdateMany(context.TODO(),
+		bson.D{{Key: "rating_avg", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "rating_avg", Value: 0},
+			{Key: "rating_count", Value: 0},
+		}}})
+	if err != nil {
+		return fmt.Errorf("update [recipe] rating_avg failed, err: %v", err)
+	}
+
+	// 为没有版本号的菜谱补充默认值，第一次修改前会保存这个版本的快照
+	_, err = collection.UpdateMany(context.TODO(),
+		bson.D{{Key: "version", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "version", Value: 1},
+			{Key: "parent_id", Value: 0},
+		}}})
+	if err != nil {
+		return fmt.Errorf("update [recipe] version failed, err: %v", err)
+	}
+
+	// 创建菜谱版本的唯一索引，每个菜谱的版本号不重复
+	_, err = g.MongoDB.Database("food").Collection("recipe_version").Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "recipe_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetName("recipe_id_version").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("create [recipe_version] recipe_id_version index failed, err: %v", err)
+	}
+
+	g.Logger.Infof("create mongodb indexes successfully")
+	return nil
+}
+
+// seedCollectCount 为菜谱补充收藏次数的默认值，并且只在第一次连接MySQL时用收藏记录回填一次收藏次数，
+// 之后由收藏和取消收藏时的增量更新维护
+func seedCollectCount(collection *mongo.Collection) error {
+	// 为没有收藏次数的菜谱补充默认值
+	_, err := collection.UpdateMany(context.TODO(),
+		bson.D{{Key: "collect_count", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "collect_count", Value: 0}}}})
+	if err != nil {
+		return err
+	}
+
+	// 没有连接MySQL时（例如导入菜谱的命令）无法回填
+	if g.MysqlDB == nil {
+		return nil
+	}
+
+	// 已经回填过时直接返回
+	migration := g.MongoDB.Database("food").Collection("migration")
+	marker := bson.D{{Key: "_id", Value: "collect_count"}}
+	n, err := migration.CountDocuments(context.TODO(), marker)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// 按菜谱分组统计收藏次数
+	counts, err := User().Collect().GetRecipeCollectionCounts(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	// 先将所有菜谱的收藏次数置为0，再按顺序写入每个菜谱的收藏次数
+	models := make([]mongo.WriteModel, 0, len(counts)+1)
+	models = append(models, mongo.NewUpdateManyModel().
+		SetFilter(bson.D{}).
+		SetUpdate(bson.D{{Key: "$set", Value: bson.D{{Key: "collect_count", Value: 0}}}}))
+	for recipeId, cnt := range counts {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "recipe_id", Value: recipeId}}).
+			SetUpdate(bson.D{{Key: "$set", Value: bson.D{{Key: "collect_count", Value: cnt}}}}))
+	}
+	_, err = collection.BulkWrite(context.TODO(), models, options.BulkWrite().SetOrdered(true))
+	if err != nil {
+		return err
+	}
+
+	// 回填成功后写入标记，之后启动时不再回填
+	_, err = migration.InsertOne(context.TODO(), append(marker, bson.E{Key: "done_at", Value: time.Now()}))
+	return err
 }
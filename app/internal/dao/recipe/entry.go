@@ -0,0 +1,10 @@
+package recipe
+
+type Group struct{}
+
+// insReview 创建一个菜谱评价的实例
+var insReview = DReview{}
+
+func (g *Group) Review() *DReview {
+	return &insReview
+}
@@ -0,0 +1,95 @@
+package recipe
+
+import (
+	"context"
+	g "main/app/global"
+	"main/app/internal/model"
+)
+
+// DReview 定义一个菜谱评价的结构体，用于处理菜谱评价相关的操作
+type DReview struct{}
+
+func (d *DReview) GetReview(ctx context.Context, recipeId, userId int64) (*model.RecipeReview, error) {
+	// 创建一个菜谱评价的对象
+	review := &model.RecipeReview{}
+	// 在数据库中查找这个用户对这个菜谱的评价
+	err := g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Where("recipe_id = ? AND user_id = ?", recipeId, userId).
+		First(review).Error
+	return review, err
+}
+
+func (d *DReview) CreateReview(ctx context.Context, review *model.RecipeReview) error {
+	// 在数据库中创建评价
+	return g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Create(review).Error
+}
+
+func (d *DReview) UpdateReview(ctx context.Context, review *model.RecipeReview) error {
+	// 在数据库中更新评价的星级和内容
+	return g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Where("id = ?", review.Id).
+		Updates(map[string]interface{}{
+			"rating":  review.Rating,
+			"content": review.Content,
+		}).Error
+}
+
+func (d *DReview) DeleteReview(ctx context.Context, id int64) error {
+	// 在数据库中删除这个ID对应的评价
+	return g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Delete(&model.RecipeReview{}, id).Error
+}
+
+func (d *DReview) DeleteReviewsByRecipe(ctx context.Context, recipeId int64) error {
+	// 在数据库中删除这个菜谱的所有评价
+	return g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Where("recipe_id = ?", recipeId).
+		Delete(&model.RecipeReview{}).Error
+}
+
+func (d *DReview) GetReviewCount(ctx context.Context, recipeId int64) (int64, error) {
+	// 定义一个计数器
+	var cnt int64
+	// 在数据库中计算这个菜谱的评价数量
+	err := g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Where("recipe_id = ?", recipeId).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (d *DReview) GetReviewsWithLimit(ctx context.Context, recipeId int64, limit, page int) ([]*model.RecipeReview, error) {
+	// 定义一个菜谱评价的列表
+	var reviews []*model.RecipeReview
+	// 在数据库中分页查找这个菜谱的评价，并关联评价的用户名，最新的在前面
+	err := g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Select("recipe_review.*, user_subject.username").
+		Joins("LEFT JOIN user_subject ON user_subject.id = recipe_review.user_id").
+		Where("recipe_review.recipe_id = ?", recipeId).
+		Order("recipe_review.id DESC").
+		Limit(limit).Offset(limit * (page - 1)).
+		Find(&reviews).Error
+	return reviews, err
+}
+
+func (d *DReview) GetRatingStats(ctx context.Context, recipeId int64) (float64, int64, error) {
+	// 定义一个统计结果的对象
+	var stats struct {
+		Avg float64
+		Cnt int64
+	}
+	// 在数据库中计算这个菜谱的平均星级和评价数量
+	err := g.MysqlDB.WithContext(ctx).
+		Table("recipe_review").
+		Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS cnt").
+		Where("recipe_id = ?", recipeId).
+		Scan(&stats).Error
+	return stats.Avg, stats.Cnt, err
+}
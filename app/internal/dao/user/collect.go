@@ -33,23 +33,24 @@ func (d *DCollect) GetCollectionByType(ctx context.Context, collectType int32, u
 	return err
 }
 
-func (d *DCollect) GetCollectionById(ctx context.Context, id, userId int64) error {
+func (d *DCollect) GetCollectionById(ctx context.Context, id, userId int64) (*model.UserCollection, error) {
 	// 创建一个用户收藏的对象
 	userCollection := &model.UserCollection{}
 	// 在数据库中查找是否存在一个ID和用户ID都匹配的收藏
 	err := g.MysqlDB.WithContext(ctx).
 		Table("user_collection").
-		Select("id,user_id").
+		Select("id,user_id,collect_type,recipe_id").
 		Where("id= ? AND user_id = ?", id, userId).
 		First(userCollection).Error
-	return err
+	return userCollection, err
 }
 
-func (d *DCollect) CreateCollection(ctx context.Context, userCollection *model.UserCollection) {
+func (d *DCollect) CreateCollection(ctx context.Context, userCollection *model.UserCollection) error {
 	// 在数据库中创建收藏
-	g.MysqlDB.WithContext(ctx).
+	err := g.MysqlDB.WithContext(ctx).
 		Table("user_collection").
-		Create(userCollection)
+		Create(userCollection).Error
+	return err
 }
 
 func (d *DCollect) DeleteCollection(ctx context.Context, id int64) error {
@@ -82,3 +83,61 @@ func (d *DCollect) GetUserCollectionsWithLimit(ctx context.Context, userId int64
 		Find(&userCollections).Error
 	return userCollections, err
 }
+
+func (d *DCollect) GetRecipeCollection(ctx context.Context, userId, recipeId int64) (*model.UserCollection, error) {
+	// 创建一个用户收藏的对象
+	userCollection := &model.UserCollection{}
+	// 在数据库中查找这个用户对这个菜谱的收藏
+	err := g.MysqlDB.WithContext(ctx).
+		Table("user_collection").
+		Where("user_id = ? AND collect_type = ? AND recipe_id = ?", userId, 2, recipeId).
+		First(userCollection).Error
+	return userCollection, err
+}
+
+func (d *DCollect) GetRecipeCollectionCounts(ctx context.Context) (map[int64]int64, error) {
+	// 定义一个统计结果的列表
+	var stats []struct {
+		RecipeId int64
+		Cnt      int64
+	}
+	// 在数据库中按菜谱分组计算每个菜谱被收藏的次数
+	err := g.MysqlDB.WithContext(ctx).
+		Table("user_collection").
+		Select("recipe_id, COUNT(*) AS cnt").
+		Where("collect_type = ?", 2).
+		Group("recipe_id").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 转换为菜谱ID到收藏次数的映射
+	counts := make(map[int64]int64, len(stats))
+	for _, stat := range stats {
+		counts[stat.RecipeId] = stat.Cnt
+	}
+	return counts, nil
+}
+
+func (d *DCollect) GetUserRecipeIds(ctx context.Context, userId int64, limit int) ([]int64, error) {
+	// 定义一个菜谱ID的列表
+	var recipeIds []int64
+	// 在数据库中查找这个用户最近收藏的菜谱ID
+	err := g.MysqlDB.WithContext(ctx).
+		Table("user_collection").
+		Where("user_id = ? AND collect_type = ?", userId, 2).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("recipe_id", &recipeIds).Error
+	return recipeIds, err
+}
+
+func (d *DCollect) DeleteRecipeCollections(ctx context.Context, recipeId int64) error {
+	// 在数据库中删除所有用户对这个菜谱的收藏
+	err := g.MysqlDB.WithContext(ctx).
+		Table("user_collection").
+		Where("collect_type = ? AND recipe_id = ?", 2, recipeId).
+		Delete(&model.UserCollection{}).Error
+	return err
+}
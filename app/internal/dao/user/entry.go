@@ -15,3 +15,17 @@ var insCollect = DCollect{}
 func (g *Group) Collect() *DCollect {
 	return &insCollect
 }
+
+// insPlan 创建一个膳食计划的实例
+var insPlan = DPlan{}
+
+func (g *Group) Plan() *DPlan {
+	return &insPlan
+}
+
+// insShopping 创建一个购物清单的实例
+var insShopping = DShopping{}
+
+func (g *Group) Shopping() *DShopping {
+	return &insShopping
+}
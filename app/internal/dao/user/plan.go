@@ -0,0 +1,93 @@
+package user
+
+import (
+	"context"
+	"gorm.io/gorm"
+	g "main/app/global"
+	"main/app/internal/model"
+)
+
+// DPlan 定义一个膳食计划的结构体，用于处理膳食计划相关的操作
+type DPlan struct{}
+
+func (d *DPlan) GetPlanById(ctx context.Context, id, userId int64) (*model.MealPlan, error) {
+	// 创建一个膳食计划的对象
+	mealPlan := &model.MealPlan{}
+	// 在数据库中查找是否存在一个ID和用户ID都匹配的膳食计划
+	err := g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Where("id = ? AND user_id = ?", id, userId).
+		First(mealPlan).Error
+	return mealPlan, err
+}
+
+func (d *DPlan) GetPlansByDate(ctx context.Context, userId int64, from, to string) ([]*model.MealPlan, error) {
+	// 定义一个膳食计划的列表
+	var mealPlans []*model.MealPlan
+	// 在数据库中查找这个用户在日期范围内的膳食计划，包括开始和结束的日期
+	err := g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Where("user_id = ? AND plan_date >= ? AND plan_date <= ?", userId, from, to).
+		Order("plan_date, id").
+		Find(&mealPlans).Error
+	return mealPlans, err
+}
+
+func (d *DPlan) CreatePlan(ctx context.Context, mealPlan *model.MealPlan) error {
+	// 在数据库中创建膳食计划
+	return g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Create(mealPlan).Error
+}
+
+func (d *DPlan) CreatePlans(ctx context.Context, mealPlans []*model.MealPlan) error {
+	// 在数据库中批量创建膳食计划
+	return g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		CreateInBatches(mealPlans, 100).Error
+}
+
+func (d *DPlan) UpdatePlan(ctx context.Context, mealPlan *model.MealPlan) error {
+	// 在数据库中更新膳食计划的日期、餐次、菜谱和份数
+	return g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Where("id = ? AND user_id = ?", mealPlan.Id, mealPlan.UserId).
+		Updates(map[string]interface{}{
+			"plan_date": mealPlan.PlanDate,
+			"meal":      mealPlan.Meal,
+			"recipe_id": mealPlan.RecipeId,
+			"servings":  mealPlan.Servings,
+		}).Error
+}
+
+func (d *DPlan) DeletePlan(ctx context.Context, id, userId int64) error {
+	// 在数据库中删除这个ID对应的膳食计划
+	return g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Where("user_id = ?", userId).
+		Delete(&model.MealPlan{}, id).Error
+}
+
+func (d *DPlan) DeletePlansByRecipe(ctx context.Context, recipeId int64) error {
+	// 在数据库中删除所有用户安排了这个菜谱的膳食计划
+	return g.MysqlDB.WithContext(ctx).
+		Table("meal_plan").
+		Where("recipe_id = ?", recipeId).
+		Delete(&model.MealPlan{}).Error
+}
+
+func (d *DPlan) ReplacePlansByDate(ctx context.Context, userId int64, from, to string, mealPlans []*model.MealPlan) error {
+	// 在一个事务中删除这个用户在日期范围内的膳食计划，并批量创建新的膳食计划
+	return g.MysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("meal_plan").
+			Where("user_id = ? AND plan_date >= ? AND plan_date <= ?", userId, from, to).
+			Delete(&model.MealPlan{}).Error
+		if err != nil {
+			return err
+		}
+		if len(mealPlans) == 0 {
+			return nil
+		}
+		return tx.Table("meal_plan").CreateInBatches(mealPlans, 100).Error
+	})
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"context"
+	"gorm.io/gorm"
+	g "main/app/global"
+	"main/app/internal/model"
+)
+
+// DShopping 定义一个购物清单的结构体，用于处理购物清单相关的操作
+type DShopping struct{}
+
+func (d *DShopping) CreateList(ctx context.Context, shoppingList *model.ShoppingList) error {
+	// 在一个事务中创建购物清单和清单中的每一项
+	return g.MysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("shopping_list").Create(shoppingList).Error; err != nil {
+			return err
+		}
+		if len(shoppingList.Items) == 0 {
+			return nil
+		}
+		for _, item := range shoppingList.Items {
+			item.ListId = shoppingList.Id
+		}
+		return tx.Table("shopping_item").CreateInBatches(shoppingList.Items, 100).Error
+	})
+}
+
+func (d *DShopping) GetListById(ctx context.Context, id, userId int64) (*model.ShoppingList, error) {
+	// 创建一个购物清单的对象
+	shoppingList := &model.ShoppingList{}
+	// 在数据库中查找是否存在一个ID和用户ID都匹配的购物清单
+	err := g.MysqlDB.WithContext(ctx).
+		Table("shopping_list").
+		Where("id = ? AND user_id = ?", id, userId).
+		First(shoppingList).Error
+	return shoppingList, err
+}
+
+func (d *DShopping) GetListsByUser(ctx context.Context, userId int64) ([]*model.ShoppingList, error) {
+	// 定义一个购物清单的列表
+	var shoppingLists []*model.ShoppingList
+	// 在数据库中查找这个用户的购物清单，最新的在前面
+	err := g.MysqlDB.WithContext(ctx).
+		Table("shopping_list").
+		Where("user_id = ?", userId).
+		Order("id DESC").
+		Find(&shoppingLists).Error
+	return shoppingLists, err
+}
+
+func (d *DShopping) GetItemsByListId(ctx context.Context, listId int64) ([]*model.ShoppingItem, error) {
+	// 定义一个购物清单项的列表
+	var items []*model.ShoppingItem
+	// 在数据库中查找购物清单中的每一项
+	err := g.MysqlDB.WithContext(ctx).
+		Table("shopping_item").
+		Where("list_id = ?", listId).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+
+func (d *DShopping) GetItemById(ctx context.Context, listId, itemId int64) (*model.ShoppingItem, error) {
+	// 创建一个购物清单项的对象
+	item := &model.ShoppingItem{}
+	// 在数据库中查找购物清单中的这一项
+	err := g.MysqlDB.WithContext(ctx).
+		Table("shopping_item").
+		Where("id = ? AND list_id = ?", itemId, listId).
+		First(item).Error
+	return item, err
+}
+
+func (d *DShopping) UpdateItemChecked(ctx context.Context, itemId int64, checked bool) error {
+	// 在数据库中更新购物清单项是否已经购买
+	return g.MysqlDB.WithContext(ctx).
+		Table("shopping_item").
+		Where("id = ?", itemId).
+		Update("checked", checked).Error
+}
+
+func (d *DShopping) DeleteList(ctx context.Context, id int64) error {
+	// 在一个事务中删除购物清单和清单中的每一项
+	return g.MysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("shopping_item").Where("list_id = ?", id).Delete(&model.ShoppingItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Table("shopping_list").Delete(&model.ShoppingList{}, id).Error
+	})
+}
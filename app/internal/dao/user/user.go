@@ -28,6 +28,34 @@ func (d *DUser) CreateUser(ctx context.Context, userSubject *model.UserSubject)
 		Create(userSubject)
 }
 
+func (d *DUser) GetUserById(ctx context.Context, id int64) (*model.UserSubject, error) {
+	// 创建一个用户对象
+	userSubject := &model.UserSubject{}
+	// 在数据库中查找用户，不查询密码
+	err := g.MysqlDB.WithContext(ctx).
+		Table("user_subject").
+		Select("id", "username", "units", "allergens", "create_time", "update_time").
+		Where("id = ?", id).
+		First(userSubject).Error
+	return userSubject, err
+}
+
+func (d *DUser) UpdateUnits(ctx context.Context, id int64, units string) error {
+	// 在数据库中更新用户默认的单位制
+	return g.MysqlDB.WithContext(ctx).
+		Table("user_subject").
+		Where("id = ?", id).
+		Update("units", units).Error
+}
+
+func (d *DUser) UpdateAllergens(ctx context.Context, id int64, allergens string) error {
+	// 在数据库中更新用户的过敏原
+	return g.MysqlDB.WithContext(ctx).
+		Table("user_subject").
+		Where("id = ?", id).
+		Update("allergens", allergens).Error
+}
+
 func (d *DUser) GetUserByUsernameAndPassword(ctx context.Context, userSubject *model.UserSubject) error {
 	// 在数据库中查找用户名和密码都匹配的用户
 	err := g.MysqlDB.WithContext(ctx).
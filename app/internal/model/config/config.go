@@ -7,5 +7,8 @@ type Config struct {
 	Server     *Server   `mapstructure:"server"  yaml:"server"`
 	Cors       CORS      `mapstructure:"cors" yaml:"cors"`
 	Auth       Auth      `mapstructure:"auth" yaml:"auth"`
+	Upload     *Upload   `mapstructure:"upload" yaml:"upload"`
 	YelpApiKey string    `mapstructure:"yelpApiKey" yaml:"yelpApiKey"`
+
+	SubstitutionPath string `mapstructure:"substitutionPath" yaml:"substitutionPath"`
 }
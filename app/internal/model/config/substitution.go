@@ -0,0 +1,22 @@
+package config
+
+// Substitution 定义食材替换的知识库，规则按顺序匹配，每个食材只使用第一个匹配的规则
+type Substitution struct {
+	Rules []*SubstitutionRule `mapstructure:"rules" yaml:"rules" json:"rules"`
+}
+
+type SubstitutionRule struct {
+	Name         string         `mapstructure:"name" yaml:"name" json:"name"`                         // 食材的名称
+	Pattern      string         `mapstructure:"pattern" yaml:"pattern" json:"pattern"`                // 匹配食材的正则表达式，为空时按名称匹配
+	Diets        []string       `mapstructure:"diets" yaml:"diets" json:"diets"`                      // 食材不符合的饮食习惯，例如vegan
+	Allergens    []string       `mapstructure:"allergens" yaml:"allergens" json:"allergens"`          // 食材含有的过敏原，例如milk
+	Replacements []*Replacement `mapstructure:"replacements" yaml:"replacements" json:"replacements"` // 可以替换的食材，按推荐程度排序
+}
+
+type Replacement struct {
+	Name      string   `mapstructure:"name" yaml:"name" json:"name"`                // 替换的食材
+	Ratio     string   `mapstructure:"ratio" yaml:"ratio" json:"ratio"`             // 替换的用量，例如"3/4 cup per cup"
+	Note      string   `mapstructure:"note" yaml:"note" json:"note"`                // 替换的说明
+	Diets     []string `mapstructure:"diets" yaml:"diets" json:"diets"`             // 替换的食材不符合的饮食习惯
+	Allergens []string `mapstructure:"allergens" yaml:"allergens" json:"allergens"` // 替换的食材含有的过敏原
+}
@@ -0,0 +1,61 @@
+package config
+
+import "strings"
+
+type Upload struct {
+	Path           string `mapstructure:"path" yaml:"path"`
+	Route          string `mapstructure:"route" yaml:"route"`
+	UrlPrefix      string `mapstructure:"urlPrefix" yaml:"urlPrefix"`
+	MaxSize        int64  `mapstructure:"maxSize" yaml:"maxSize"`
+	MaxPixels      int64  `mapstructure:"maxPixels" yaml:"maxPixels"`
+	ThumbnailSizes []int  `mapstructure:"thumbnailSizes" yaml:"thumbnailSizes"`
+}
+
+// GetPath 获取本地存储图片的目录，没有配置时使用默认值
+func (u *Upload) GetPath() string {
+	if u == nil || u.Path == "" {
+		return "static/images"
+	}
+	return u.Path
+}
+
+// GetRoute 获取提供静态文件的路由，没有配置时使用默认值
+func (u *Upload) GetRoute() string {
+	if u == nil || u.Route == "" {
+		return "/static/images"
+	}
+	return u.Route
+}
+
+// GetUrlPrefix 获取图片URL的前缀，没有配置时使用网站的地址拼接静态文件的路由
+func (u *Upload) GetUrlPrefix(prefixUrl string) string {
+	if u == nil || u.UrlPrefix == "" {
+		return strings.TrimRight(prefixUrl, "/") + u.GetRoute()
+	}
+	return u.UrlPrefix
+}
+
+// GetMaxSize 获取单张图片的最大字节数，没有配置时为5MB
+func (u *Upload) GetMaxSize() int64 {
+	if u == nil || u.MaxSize <= 0 {
+		return 5 << 20
+	}
+	return u.MaxSize
+}
+
+// GetMaxPixels 获取单张图片的最大像素数，防止解码过大的图片，没有配置时为4000万
+func (u *Upload) GetMaxPixels() int64 {
+	if u == nil || u.MaxPixels <= 0 {
+		return 40000000
+	}
+	return u.MaxPixels
+}
+
+// GetThumbnailSizes 获取缩略图的宽度，没有配置时使用默认值
+// 缩略图的URL由原图的URL和宽度推导，修改配置后已经上传的图片不会重新生成缩略图
+func (u *Upload) GetThumbnailSizes() []int {
+	if u == nil || len(u.ThumbnailSizes) == 0 {
+		return []int{160, 480, 960}
+	}
+	return u.ThumbnailSizes
+}
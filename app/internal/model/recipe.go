@@ -1,25 +1,201 @@
 package model
 
+import (
+	"main/utils/diff"
+	"main/utils/ingredient"
+	"time"
+)
+
 type Recipe struct {
-	Id           string   `bson:"_id"`
-	RecipeId     int64    `bson:"recipe_id"`
-	Images       []string `bson:"images"`
-	Name         string   `bson:"name"`
-	Category     string   `bson:"category"`
-	Dietary      []string `bson:"dietary"`
-	Description  string   `bson:"description"`
-	Keywords     []string `bson:"keywords"`
-	Instruction  []string `bson:"instruction"`
-	Ingredients  []string `bson:"ingredients"`
-	CookTime     int64    `bson:"cook_time"`
-	PerpTime     int64    `bson:"perp_time"`
-	TotalTime    int64    `bson:"total_time"`
-	Calories     float64  `bson:"calories"`
-	Fat          float64  `bson:"fat"`
-	SaturatedFat float64  `bson:"saturated_fat"`
-	Sodium       float64  `bson:"sodium"`
-	Carbohydrate float64  `bson:"carbohydrate"`
-	Fiber        float64  `bson:"fiber"`
-	Sugar        float64  `bson:"sugar"`
-	Protein      float64  `bson:"protein"`
+	Id           string    `bson:"_id,omitempty"`
+	RecipeId     int64     `bson:"recipe_id"`
+	Images       []string  `bson:"images"` // 图片的URL，上传的图片保存为recipes/{菜谱ID}/{名称}.{扩展名}，缩略图为同目录下的{名称}_{宽度}.jpg
+	Name         string    `bson:"name"`
+	Category     string    `bson:"category"`
+	Dietary      []string  `bson:"dietary"`
+	Description  string    `bson:"description"`
+	Keywords     []string  `bson:"keywords"`
+	Instruction  []string  `bson:"instruction"`
+	Ingredients  []string  `bson:"ingredients"`
+	Servings     int64     `bson:"servings"` // 菜谱的份数，食材和营养成分对应这个份数，为0时视为1份
+	CookTime     int64     `bson:"cook_time"`
+	PerpTime     int64     `bson:"perp_time"`
+	TotalTime    int64     `bson:"total_time"`
+	Calories     float64   `bson:"calories"`
+	Fat          float64   `bson:"fat"`
+	SaturatedFat float64   `bson:"saturated_fat"`
+	Sodium       float64   `bson:"sodium"`
+	Carbohydrate float64   `bson:"carbohydrate"`
+	Fiber        float64   `bson:"fiber"`
+	Sugar        float64   `bson:"sugar"`
+	Protein      float64   `bson:"protein"`
+	CollectCount int64     `bson:"collect_count"`   // 被收藏的次数
+	RatingAvg    float64   `bson:"rating_avg"`      // 评价的平均星级
+	RatingCount  int64     `bson:"rating_count"`    // 评价的数量
+	OwnerId      int64     `bson:"owner_id"`        // 创建菜谱的用户ID，预置的菜谱为0
+	ParentId     int64     `bson:"parent_id"`       // 复制来源的菜谱ID，不是复制的菜谱为0
+	Version      int64     `bson:"version"`         // 当前的版本号，每次修改加1
+	Visibility   string    `bson:"visibility"`      // 可见性，public或private，为空时视为public
+	CreateTime   time.Time `bson:"create_time"`     // 创建时间
+	UpdateTime   time.Time `bson:"update_time"`     // 更新时间
+	Score        float64   `bson:"score,omitempty"` // 全文搜索的相关度得分
+}
+
+// RecipeForm 定义用户创建和更新菜谱时提交的内容
+type RecipeForm struct {
+	Images       []string `json:"images" form:"images"`
+	Name         string   `json:"name" form:"name"`
+	Category     string   `json:"category" form:"category"`
+	Dietary      []string `json:"dietary" form:"dietary"`
+	Description  string   `json:"description" form:"description"`
+	Keywords     []string `json:"keywords" form:"keywords"`
+	Instruction  []string `json:"instruction" form:"instruction"`
+	Ingredients  []string `json:"ingredients" form:"ingredients"`
+	Servings     int64    `json:"servings" form:"servings"`
+	CookTime     int64    `json:"cook_time" form:"cook_time"`
+	PerpTime     int64    `json:"perp_time" form:"perp_time"`
+	TotalTime    int64    `json:"total_time" form:"total_time"`
+	Calories     float64  `json:"calories" form:"calories"`
+	Fat          float64  `json:"fat" form:"fat"`
+	SaturatedFat float64  `json:"saturated_fat" form:"saturated_fat"`
+	Sodium       float64  `json:"sodium" form:"sodium"`
+	Carbohydrate float64  `json:"carbohydrate" form:"carbohydrate"`
+	Fiber        float64  `json:"fiber" form:"fiber"`
+	Sugar        float64  `json:"sugar" form:"sugar"`
+	Protein      float64  `json:"protein" form:"protein"`
+	Visibility   string   `json:"visibility" form:"visibility"`
+}
+
+type RecipeDetail struct {
+	Recipe            *Recipe                   `json:"recipe"`
+	ParsedIngredients []*ingredient.Ingredient  `json:"parsed_ingredients"`
+	IsCollected       bool                      `json:"is_collected"`
+	CollectionId      int64                     `json:"collection_id"`
+	CollectCount      int64                     `json:"collect_count"`
+	Thumbnails        map[string]map[int]string `json:"thumbnails,omitempty"` // 上传的图片的URL到各个宽度的缩略图URL的映射
+}
+
+// Nutrition 定义营养成分的合计
+type Nutrition struct {
+	Calories     float64 `json:"calories"`
+	Fat          float64 `json:"fat"`
+	SaturatedFat float64 `json:"saturated_fat"`
+	Sodium       float64 `json:"sodium"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fiber        float64 `json:"fiber"`
+	Sugar        float64 `json:"sugar"`
+	Protein      float64 `json:"protein"`
+}
+
+// RecipeScore 定义一个菜谱和它的得分，例如相似度和推荐的得分，得分在0到1之间
+type RecipeScore struct {
+	Recipe *Recipe `json:"recipe"`
+	Score  float64 `json:"score"`
+}
+
+// MenuItem 定义一日菜单中的一餐，营养成分为一份的营养成分
+type MenuItem struct {
+	Meal      string     `json:"meal"`
+	Recipe    *Recipe    `json:"recipe"`
+	Nutrition *Nutrition `json:"nutrition"`
+}
+
+// DayMenu 定义一日菜单，包括每一餐、营养成分的合计和目标
+type DayMenu struct {
+	Meals  []*MenuItem `json:"meals"`
+	Total  *Nutrition  `json:"total"`
+	Target *Nutrition  `json:"target"`
+}
+
+type RecipeFacet struct {
+	Value string   `json:"value"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+type RecipeMatch struct {
+	Recipe   *Recipe  `bson:"recipe" json:"recipe"`
+	Coverage float64  `bson:"coverage" json:"coverage"`
+	Matched  []string `bson:"matched" json:"matched"`
+	Missing  []string `bson:"missing" json:"missing"`
+}
+
+type RecipeImportError struct {
+	Line     int    `json:"line,omitempty"`
+	RecipeId int64  `json:"recipe_id,omitempty"`
+	Msg      string `json:"msg"`
+}
+
+type RecipeImage struct {
+	Url         string         `json:"url"`
+	ContentType string         `json:"content_type"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	Thumbnails  map[int]string `json:"thumbnails"`
+}
+
+type CookingStep struct {
+	Step         int                `json:"step"`
+	Text         string             `json:"text"`
+	Timers       []*StepTimer       `json:"timers"`
+	Temperatures []*StepTemperature `json:"temperatures"`
+}
+
+type StepTimer struct {
+	Label      string `json:"label"`
+	Text       string `json:"text"`
+	Seconds    int64  `json:"seconds"`
+	MaxSeconds int64  `json:"max_seconds"`
+}
+
+type StepTemperature struct {
+	Text       string  `json:"text"`
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type CookingMode struct {
+	RecipeId     int64          `json:"recipe_id"`
+	Name         string         `json:"name"`
+	TotalSteps   int            `json:"total_steps"`
+	TotalSeconds int64          `json:"total_seconds"` // 每个步骤中最长的计时器的合计
+	Steps        []*CookingStep `json:"steps"`
+}
+
+type IngredientSubstitution struct {
+	Index        int                 `json:"index"`
+	Ingredient   string              `json:"ingredient"`
+	Rule         string              `json:"rule"`
+	Reasons      []string            `json:"reasons"`
+	Replacements []*SubstituteOption `json:"replacements"`
+}
+
+type SubstituteOption struct {
+	Name  string `json:"name"`
+	Ratio string `json:"ratio"`
+	Note  string `json:"note"`
+}
+
+type RecipeVersion struct {
+	RecipeId   int64     `bson:"recipe_id" json:"recipe_id"`
+	Version    int64     `bson:"version" json:"version"`
+	Action     string    `bson:"action" json:"action"`
+	UserId     int64     `bson:"user_id" json:"user_id"`
+	Recipe     *Recipe   `bson:"recipe,omitempty" json:"recipe,omitempty"`
+	CreateTime time.Time `bson:"create_time" json:"create_time"`
+}
+
+type RecipeDiff struct {
+	RecipeId int64           `json:"recipe_id"`
+	From     int64           `json:"from"`
+	To       int64           `json:"to"`
+	Changes  []*RecipeChange `json:"changes"`
+}
+
+type RecipeChange struct {
+	Field string       `json:"field"`
+	Old   interface{}  `json:"old,omitempty"`
+	New   interface{}  `json:"new,omitempty"`
+	Lines []*diff.Edit `json:"lines,omitempty"`
 }
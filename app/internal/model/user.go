@@ -8,10 +8,16 @@ type UserSubject struct {
 	Id         int64     `json:"id" form:"id" db:"id"`
 	Username   string    `json:"username" form:"username" db:"username"`
 	Password   string    `json:"password" form:"password" db:"password"`
+	Units      string    `gorm:"size:16;not null;default:''" json:"units" form:"units" db:"units"`              // 菜谱默认使用的单位制，metric或imperial，为空时不转换
+	Allergens  string    `gorm:"size:255;not null;default:''" json:"allergens" form:"allergens" db:"allergens"` // 用户的过敏原，使用逗号分隔
 	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time" form:"create_time" db:"create_time"`
 	UpdateTime time.Time `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
 }
 
+func (UserSubject) TableName() string {
+	return "user_subject"
+}
+
 type UserCollection struct {
 	Id           int64     `json:"id" form:"id" db:"id"`
 	UserId       int64     `json:"user_id" form:"user_id" db:"user_id"`
@@ -22,6 +28,88 @@ type UserCollection struct {
 	UpdateTime   time.Time `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
 }
 
+func (UserCollection) TableName() string {
+	return "user_collection"
+}
+
+// RecipeReview 定义用户对菜谱的评价，每个用户对每个菜谱只能评价一次
+type RecipeReview struct {
+	Id         int64     `json:"id" form:"id" db:"id"`
+	RecipeId   int64     `gorm:"uniqueIndex:idx_recipe_review_recipe_user" json:"recipe_id" form:"recipe_id" db:"recipe_id"`
+	UserId     int64     `gorm:"uniqueIndex:idx_recipe_review_recipe_user" json:"user_id" form:"user_id" db:"user_id"`
+	Username   string    `gorm:"->;-:migration" json:"username" form:"username" db:"username"` // 评价的用户名，只在查询时关联获取
+	Rating     int32     `json:"rating" form:"rating" db:"rating"`                             // 星级，1到5
+	Content    string    `gorm:"type:text" json:"content" form:"content" db:"content"`
+	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time" form:"create_time" db:"create_time"`
+	UpdateTime time.Time `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
+}
+
+func (RecipeReview) TableName() string {
+	return "recipe_review"
+}
+
+// MealPlan 定义膳食计划中的一项，表示在某一天的某一餐安排一个菜谱
+type MealPlan struct {
+	Id         int64     `json:"id" form:"id" db:"id"`
+	UserId     int64     `gorm:"index:idx_meal_plan_user_date" json:"user_id" form:"user_id" db:"user_id"`
+	PlanDate   string    `gorm:"size:10;index:idx_meal_plan_user_date" json:"plan_date" form:"plan_date" db:"plan_date"` // 日期，格式为2006-01-02
+	Meal       string    `gorm:"size:16" json:"meal" form:"meal" db:"meal"`                                              // 餐次，breakfast、lunch、dinner或snack
+	RecipeId   int64     `json:"recipe_id" form:"recipe_id" db:"recipe_id"`
+	Servings   int64     `json:"servings" form:"servings" db:"servings"` // 份数
+	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time" form:"create_time" db:"create_time"`
+	UpdateTime time.Time `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
+}
+
+func (MealPlan) TableName() string {
+	return "meal_plan"
+}
+
+// MealPlanEntry 定义膳食计划的一餐和对应的菜谱
+type MealPlanEntry struct {
+	Id       int64   `json:"id"`
+	Meal     string  `json:"meal"`
+	RecipeId int64   `json:"recipe_id"`
+	Servings int64   `json:"servings"`
+	Recipe   *Recipe `json:"recipe"` // 菜谱被删除或者不可见时为空
+}
+
+// MealPlanDay 定义膳食计划的一天，包括每一餐和营养成分的合计
+type MealPlanDay struct {
+	Date      string           `json:"date"`
+	Meals     []*MealPlanEntry `json:"meals"`
+	Nutrition *Nutrition       `json:"nutrition"`
+}
+
+// ShoppingList 定义用户的购物清单
+type ShoppingList struct {
+	Id         int64           `json:"id" form:"id" db:"id"`
+	UserId     int64           `gorm:"index" json:"user_id" form:"user_id" db:"user_id"`
+	Name       string          `gorm:"size:100" json:"name" form:"name" db:"name"`
+	CreateTime time.Time       `gorm:"autoCreateTime" json:"create_time" form:"create_time" db:"create_time"`
+	UpdateTime time.Time       `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
+	Items      []*ShoppingItem `gorm:"-" json:"items,omitempty"`
+}
+
+func (ShoppingList) TableName() string {
+	return "shopping_list"
+}
+
+// ShoppingItem 定义购物清单中的一项，相同的食材会合并为一项
+type ShoppingItem struct {
+	Id         int64     `json:"id" form:"id" db:"id"`
+	ListId     int64     `gorm:"index" json:"list_id" form:"list_id" db:"list_id"`
+	Name       string    `gorm:"size:200" json:"name" form:"name" db:"name"`
+	Quantity   float64   `json:"quantity" form:"quantity" db:"quantity"` // 数量，没有数量的食材为0
+	Unit       string    `gorm:"size:32" json:"unit" form:"unit" db:"unit"`
+	Checked    bool      `json:"checked" form:"checked" db:"checked"` // 是否已经购买
+	CreateTime time.Time `gorm:"autoCreateTime" json:"create_time" form:"create_time" db:"create_time"`
+	UpdateTime time.Time `gorm:"autoUpdateTime" json:"update_time" form:"update_time" db:"update_time"`
+}
+
+func (ShoppingItem) TableName() string {
+	return "shopping_item"
+}
+
 type Collection struct {
 	Id             int64       `json:"id"`
 	CollectionType string      `json:"collection_type"`
@@ -0,0 +1,60 @@
+package recipe
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+)
+
+// Allergens 定义主要的过敏原和对应食材的正则表达式
+// 宁可多排除，也不要漏掉含有过敏原的菜谱，例如almond flour也会被当作含有wheat
+var Allergens = map[string]string{
+	"peanut":    `\bpeanuts?\b`,
+	"tree_nut":  `\b(almond|cashew|walnut|pecan|pistachio|hazelnut|macadamia|brazil nut|pine nut|chestnut|praline|marzipan)`,
+	"milk":      `\b(milk|butter|buttermilk|cheese|cream|creme|yogh?urt|whey|ghee|casein|custard|mozzarella|parmesan|ricotta|cheddar)`,
+	"egg":       `\beggs?\b|\b(egg yolk|egg white|mayonnaise|meringue)`,
+	"fish":      `\b(fish|salmon|tuna|cod|anchov|sardine|trout|tilapia|halibut|mackerel|haddock|herring|snapper|bass)`,
+	"shellfish": `\b(shrimp|prawn|crab|lobster|clam|mussel|oyster|scallop|crayfish|crawfish|squid|calamari|octopus)`,
+	"wheat":     `\b(wheat|flour|bread|breadcrumb|pasta|noodle|couscous|semolina|bulgur|farro|cracker|tortilla)`,
+	"gluten":    `\b(wheat|flour|bread|breadcrumb|pasta|noodle|couscous|semolina|bulgur|farro|cracker|tortilla|barley|rye|malt|spelt|seitan)`,
+	"soy":       `\b(soy|soya|tofu|edamame|miso|tempeh|tamari)`,
+	"sesame":    `\b(sesame|tahini)`,
+}
+
+// GetExcludeFilter 获取排除食材和过敏原的过滤条件，任意一个食材匹配的菜谱都会被排除
+func (s *SSearch) GetExcludeFilter(excludeIngredients, allergens []string) (bson.E, error) {
+	// 定义需要排除的条件的列表
+	nor := bson.A{}
+
+	// 对于每一个需要排除的食材，按字面值忽略大小写匹配
+	for _, ingredient := range excludeIngredients {
+		if ingredient == "" {
+			continue
+		}
+		nor = append(nor, bson.D{{Key: "ingredients", Value: primitive.Regex{
+			Pattern: regexp.QuoteMeta(ingredient),
+			Options: "i",
+		}}})
+	}
+
+	// 对于每一个过敏原，使用内置的正则表达式匹配
+	for _, allergen := range allergens {
+		pattern, ok := Allergens[allergen]
+		if !ok {
+			return bson.E{}, fmt.Errorf(`invalid allergen "%s"`, allergen)
+		}
+		nor = append(nor, bson.D{{Key: "ingredients", Value: primitive.Regex{
+			Pattern: pattern,
+			Options: "i",
+		}}})
+	}
+
+	// 如果没有需要排除的条件，返回空的过滤条件
+	if len(nor) == 0 {
+		return bson.E{}, nil
+	}
+
+	// 排除匹配任意一个条件的菜谱
+	return bson.E{Key: "$nor", Value: nor}, nil
+}
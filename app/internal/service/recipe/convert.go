@@ -0,0 +1,34 @@
+package recipe
+
+import (
+	"main/app/internal/model"
+	"main/utils/ingredient"
+	"main/utils/unit"
+)
+
+// ConvertRecipe 将菜谱的食材和步骤中的温度转换到指定的单位制，会修改菜谱的食材和步骤，返回转换后解析的食材
+// 单位制为空时不转换
+func (s *SInfo) ConvertRecipe(recipe *model.Recipe, parsed []*ingredient.Ingredient, system string) []*ingredient.Ingredient {
+	if !unit.IsValid(system) {
+		return parsed
+	}
+
+	// 转换食材的单位，无法转换的食材保持不变
+	res := make([]*ingredient.Ingredient, 0, len(parsed))
+	ingredients := make([]string, 0, len(parsed))
+	for _, item := range parsed {
+		converted := item.Convert(system)
+		res = append(res, converted)
+		ingredients = append(ingredients, converted.Raw)
+	}
+	recipe.Ingredients = ingredients
+
+	// 转换步骤中的温度
+	instruction := make([]string, 0, len(recipe.Instruction))
+	for _, step := range recipe.Instruction {
+		instruction = append(instruction, unit.ConvertTemperatures(step, system))
+	}
+	recipe.Instruction = instruction
+
+	return res
+}
@@ -0,0 +1,95 @@
+package recipe
+
+import (
+	"fmt"
+	"main/app/internal/model"
+	"main/utils/duration"
+	"main/utils/unit"
+	"strings"
+)
+
+// GetCookingMode 将菜谱的步骤转换为烹饪模式，解析每个步骤中的计时器和温度
+// 单位制不为空时先转换步骤中的温度
+func (s *SInfo) GetCookingMode(recipe *model.Recipe, system string) *model.CookingMode {
+	mode := &model.CookingMode{
+		RecipeId: recipe.RecipeId,
+		Name:     recipe.Name,
+		Steps:    make([]*model.CookingStep, 0, len(recipe.Instruction)),
+	}
+
+	for _, text := range recipe.Instruction {
+		text = strings.TrimSpace(text)
+		// 跳过空的步骤
+		if text == "" {
+			continue
+		}
+		if unit.IsValid(system) {
+			text = unit.ConvertTemperatures(text, system)
+		}
+
+		step := s.parseStep(text)
+		step.Step = len(mode.Steps) + 1
+		mode.Steps = append(mode.Steps, step)
+
+		// 累加每个步骤中最长的计时器，同一步骤中的计时器通常是同时进行的，例如"cook 10 minutes, stirring every 2 minutes"
+		var longest int64
+		for _, timer := range step.Timers {
+			longest = max(longest, timer.MaxSeconds)
+		}
+		mode.TotalSeconds += longest
+	}
+	mode.TotalSteps = len(mode.Steps)
+
+	return mode
+}
+
+// GetCookingStep 获取烹饪模式的第N个步骤，N从1开始
+func (s *SInfo) GetCookingStep(mode *model.CookingMode, n int) (*model.CookingStep, error) {
+	if n <= 0 || n > len(mode.Steps) {
+		return nil, fmt.Errorf("step not found")
+	}
+
+	return mode.Steps[n-1], nil
+}
+
+// parseStep 解析一个步骤中的计时器和温度
+func (s *SInfo) parseStep(text string) *model.CookingStep {
+	step := &model.CookingStep{
+		Text:         text,
+		Timers:       []*model.StepTimer{},
+		Temperatures: []*model.StepTemperature{},
+	}
+
+	for _, span := range duration.FindAll(text) {
+		step.Timers = append(step.Timers, &model.StepTimer{
+			Label:      timerLabel(text, span),
+			Text:       span.Text,
+			Seconds:    int64(span.Min.Seconds()),
+			MaxSeconds: int64(span.Max.Seconds()),
+		})
+	}
+	for _, temp := range unit.FindTemperatures(text) {
+		step.Temperatures = append(step.Temperatures, &model.StepTemperature{
+			Text:       temp.Text,
+			Celsius:    temp.Celsius,
+			Fahrenheit: temp.Fahrenheit,
+		})
+	}
+
+	return step
+}
+
+// timerLabel 使用时间段所在的分句作为计时器的名称，例如"simmer for 20 minutes"
+func timerLabel(text string, span *duration.Span) string {
+	start := strings.LastIndexAny(text[:span.Start], ".;!?,") + 1
+	label := strings.TrimSpace(text[start:span.End])
+
+	// 去掉分句开头的连接词
+	for _, prefix := range []string{"then ", "and "} {
+		if len(label) > len(prefix) && strings.EqualFold(label[:len(prefix)], prefix) {
+			label = strings.TrimSpace(label[len(prefix):])
+		}
+	}
+
+	return label
+}
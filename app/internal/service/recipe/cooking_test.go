@@ -0,0 +1,49 @@
+package recipe
+
+import (
+	"main/app/internal/model"
+	"testing"
+)
+
+func TestGetCookingMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		instruction  []string
+		system       string
+		totalSteps   int
+		totalSeconds int64
+	}{
+		{"one timer per step", []string{"Boil 10 minutes.", "Bake 1 hour 30 minutes."}, "", 2, 6000},
+		{"longest timer of a step", []string{"Cook 10 minutes, stirring every 2 minutes."}, "", 1, 600},
+		{"upper end of a range", []string{"Simmer 10-15 mins.", "Rest 5 minutes."}, "", 2, 1200},
+		{"skip empty steps", []string{"Mix well.", " ", "Chill for half an hour."}, "", 2, 1800},
+		{"no timers", []string{"Serve."}, "", 1, 0},
+	}
+
+	s := &SInfo{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := s.GetCookingMode(&model.Recipe{Instruction: tt.instruction}, tt.system)
+			if mode.TotalSteps != tt.totalSteps || len(mode.Steps) != tt.totalSteps {
+				t.Errorf("total steps = %d, want %d", mode.TotalSteps, tt.totalSteps)
+			}
+			if mode.TotalSeconds != tt.totalSeconds {
+				t.Errorf("total seconds = %d, want %d", mode.TotalSeconds, tt.totalSeconds)
+			}
+			for i, step := range mode.Steps {
+				if step.Step != i+1 {
+					t.Errorf("step %d numbered %d", i+1, step.Step)
+				}
+			}
+		})
+	}
+
+	// 转换单位制时同时转换步骤中的温度
+	mode := s.GetCookingMode(&model.Recipe{Instruction: []string{"Bake at 350°F for 20 minutes."}}, "metric")
+	if got := mode.Steps[0].Text; got != "Bake at 180°C for 20 minutes." {
+		t.Errorf("converted step = %q", got)
+	}
+	if temps := mode.Steps[0].Temperatures; len(temps) != 1 || temps[0].Celsius != 180 {
+		t.Errorf("temperatures = %+v", temps)
+	}
+}
@@ -8,3 +8,66 @@ var insInfo = SInfo{}
 func (g *Group) Info() *SInfo {
 	return &insInfo
 }
+
+// insSearch 创建一个菜谱搜索的实例
+var insSearch = SSearch{}
+
+func (g *Group) Search() *SSearch {
+	return &insSearch
+}
+
+// insManage 创建一个管理用户菜谱的实例
+var insManage = SManage{}
+
+func (g *Group) Manage() *SManage {
+	return &insManage
+}
+
+// insImport 创建一个导入菜谱的实例
+var insImport = SImport{}
+
+func (g *Group) Import() *SImport {
+	return &insImport
+}
+
+// insExport 创建一个导出菜谱的实例
+var insExport = SExport{}
+
+func (g *Group) Export() *SExport {
+	return &insExport
+}
+
+// insMenu 创建一个生成菜单的实例
+var insMenu = SMenu{}
+
+func (g *Group) Menu() *SMenu {
+	return &insMenu
+}
+
+// insReview 创建一个菜谱评价的实例
+var insReview = SReview{}
+
+func (g *Group) Review() *SReview {
+	return &insReview
+}
+
+// insImage 创建一个菜谱图片的实例
+var insImage = SImage{}
+
+func (g *Group) Image() *SImage {
+	return &insImage
+}
+
+// insSubstitution 创建一个食材替换的实例
+var insSubstitution = SSubstitution{}
+
+func (g *Group) Substitution() *SSubstitution {
+	return &insSubstitution
+}
+
+// insVersion 创建一个菜谱版本历史的实例
+var insVersion = SVersion{}
+
+func (g *Group) Version() *SVersion {
+	return &insVersion
+}
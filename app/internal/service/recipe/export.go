@@ -0,0 +1,288 @@
+package recipe
+
+import (
+	"fmt"
+	"main/app/internal/model"
+	"main/utils/duration"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SExport 定义一个导出菜谱的结构体
+type SExport struct{}
+
+// JSONLDRecipe 定义schema.org的Recipe
+type JSONLDRecipe struct {
+	Context            string             `json:"@context"`
+	Type               string             `json:"@type"`
+	Identifier         string             `json:"identifier"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description,omitempty"`
+	Image              []string           `json:"image,omitempty"`
+	RecipeCategory     string             `json:"recipeCategory,omitempty"`
+	Keywords           string             `json:"keywords,omitempty"`
+	SuitableForDiet    []string           `json:"suitableForDiet,omitempty"`
+	PrepTime           string             `json:"prepTime,omitempty"`
+	CookTime           string             `json:"cookTime,omitempty"`
+	TotalTime          string             `json:"totalTime,omitempty"`
+	RecipeYield        string             `json:"recipeYield,omitempty"`
+	RecipeIngredient   []string           `json:"recipeIngredient"`
+	RecipeInstructions []*JSONLDHowToStep `json:"recipeInstructions"`
+	Nutrition          *JSONLDNutrition   `json:"nutrition,omitempty"`
+	DateCreated        string             `json:"dateCreated,omitempty"`
+	DateModified       string             `json:"dateModified,omitempty"`
+}
+
+// JSONLDHowToStep 定义schema.org的HowToStep
+type JSONLDHowToStep struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+}
+
+// JSONLDNutrition 定义schema.org的NutritionInformation
+type JSONLDNutrition struct {
+	Type                string `json:"@type"`
+	Calories            string `json:"calories"`
+	FatContent          string `json:"fatContent"`
+	SaturatedFatContent string `json:"saturatedFatContent"`
+	SodiumContent       string `json:"sodiumContent"`
+	CarbohydrateContent string `json:"carbohydrateContent"`
+	FiberContent        string `json:"fiberContent"`
+	SugarContent        string `json:"sugarContent"`
+	ProteinContent      string `json:"proteinContent"`
+}
+
+// dietSchemas 定义饮食习惯和schema.org的RestrictedDiet的对应关系，键为排除的标签
+var dietSchemas = []struct{ label, schema string }{
+	{"non-halal", "https://schema.org/HalalDiet"},
+	{"non-vegetarian", "https://schema.org/VegetarianDiet"},
+	{"non-vegan", "https://schema.org/VeganDiet"},
+}
+
+// ToJSONLD 将菜谱转换为schema.org的Recipe
+func (s *SExport) ToJSONLD(recipe *model.Recipe) *JSONLDRecipe {
+	res := &JSONLDRecipe{
+		Context:          "https://schema.org",
+		Type:             "Recipe",
+		Identifier:       strconv.FormatInt(recipe.RecipeId, 10),
+		Name:             recipe.Name,
+		Description:      recipe.Description,
+		Image:            recipe.Images,
+		RecipeCategory:   recipe.Category,
+		Keywords:         strings.Join(recipe.Keywords, ", "),
+		PrepTime:         isoDuration(recipe.PerpTime),
+		CookTime:         isoDuration(recipe.CookTime),
+		TotalTime:        isoDuration(recipe.TotalTime),
+		RecipeIngredient: recipe.Ingredients,
+		Nutrition: &JSONLDNutrition{
+			Type:                "NutritionInformation",
+			Calories:            fmt.Sprintf("%s calories", formatNumber(recipe.Calories)),
+			FatContent:          fmt.Sprintf("%s g", formatNumber(recipe.Fat)),
+			SaturatedFatContent: fmt.Sprintf("%s g", formatNumber(recipe.SaturatedFat)),
+			SodiumContent:       fmt.Sprintf("%s mg", formatNumber(recipe.Sodium)),
+			CarbohydrateContent: fmt.Sprintf("%s g", formatNumber(recipe.Carbohydrate)),
+			FiberContent:        fmt.Sprintf("%s g", formatNumber(recipe.Fiber)),
+			SugarContent:        fmt.Sprintf("%s g", formatNumber(recipe.Sugar)),
+			ProteinContent:      fmt.Sprintf("%s g", formatNumber(recipe.Protein)),
+		},
+	}
+
+	// 输出份数
+	if recipe.Servings > 0 {
+		res.RecipeYield = fmt.Sprintf("%d servings", recipe.Servings)
+	}
+
+	// 和搜索的规则一致，没有排除标签的菜谱视为符合对应的饮食习惯，没有标注饮食习惯的菜谱不输出
+	if recipe.Dietary != nil {
+		for _, diet := range dietSchemas {
+			if !slices.Contains(recipe.Dietary, diet.label) {
+				res.SuitableForDiet = append(res.SuitableForDiet, diet.schema)
+			}
+		}
+	}
+
+	// 每一个步骤对应一个HowToStep
+	res.RecipeInstructions = make([]*JSONLDHowToStep, 0, len(recipe.Instruction))
+	for i, step := range recipe.Instruction {
+		res.RecipeInstructions = append(res.RecipeInstructions, &JSONLDHowToStep{
+			Type:     "HowToStep",
+			Position: i + 1,
+			Text:     step,
+		})
+	}
+
+	// 输出创建时间和更新时间
+	if !recipe.CreateTime.IsZero() {
+		res.DateCreated = recipe.CreateTime.Format(time.RFC3339)
+	}
+	if !recipe.UpdateTime.IsZero() {
+		res.DateModified = recipe.UpdateTime.Format(time.RFC3339)
+	}
+
+	return res
+}
+
+// ToMarkdown 将菜谱转换为可以打印的Markdown文档
+func (s *SExport) ToMarkdown(recipe *model.Recipe) string {
+	var b strings.Builder
+
+	// 标题、图片和描述
+	name := escapeMarkdown(recipe.Name)
+	_, _ = fmt.Fprintf(&b, "# %s\n\n", name)
+	for _, image := range recipe.Images {
+		_, _ = fmt.Fprintf(&b, "![%s](%s)\n\n", name, image)
+	}
+	if recipe.Description != "" {
+		lines := strings.Split(recipe.Description, "\n")
+		for i, line := range lines {
+			lines[i] = escapeMarkdown(line)
+		}
+		_, _ = fmt.Fprintf(&b, "> %s\n\n", strings.Join(lines, "\n> "))
+	}
+
+	// 分类、关键词和时间
+	if recipe.Category != "" {
+		_, _ = fmt.Fprintf(&b, "**Category:** %s  \n", escapeMarkdown(recipe.Category))
+	}
+	if len(recipe.Keywords) > 0 {
+		keywords := make([]string, len(recipe.Keywords))
+		for i, keyword := range recipe.Keywords {
+			keywords[i] = escapeMarkdown(keyword)
+		}
+		_, _ = fmt.Fprintf(&b, "**Keywords:** %s  \n", strings.Join(keywords, ", "))
+	}
+	if recipe.Servings > 0 {
+		_, _ = fmt.Fprintf(&b, "**Servings:** %d  \n", recipe.Servings)
+	}
+	_, _ = fmt.Fprintf(&b, "**Prep:** %s · **Cook:** %s · **Total:** %s\n\n",
+		readableDuration(recipe.PerpTime), readableDuration(recipe.CookTime), readableDuration(recipe.TotalTime))
+
+	// 食材
+	b.WriteString("## Ingredients\n\n")
+	for _, ingredient := range recipe.Ingredients {
+		_, _ = fmt.Fprintf(&b, "- %s\n", escapeMarkdown(ingredient))
+	}
+
+	// 步骤
+	b.WriteString("\n## Instructions\n\n")
+	for i, step := range recipe.Instruction {
+		_, _ = fmt.Fprintf(&b, "%d. %s\n", i+1, escapeMarkdown(step))
+	}
+
+	// 营养成分
+	b.WriteString("\n## Nutrition\n\n| Nutrient | Amount |\n| --- | --- |\n")
+	for _, n := range nutritionRows(recipe) {
+		_, _ = fmt.Fprintf(&b, "| %s | %s |\n", n[0], n[1])
+	}
+
+	return b.String()
+}
+
+// ToText 将菜谱转换为纯文本
+func (s *SExport) ToText(recipe *model.Recipe) string {
+	var b strings.Builder
+
+	// 标题和描述
+	b.WriteString(recipe.Name + "\n")
+	b.WriteString(strings.Repeat("=", len([]rune(recipe.Name))) + "\n\n")
+	if recipe.Description != "" {
+		b.WriteString(recipe.Description + "\n\n")
+	}
+	if recipe.Servings > 0 {
+		_, _ = fmt.Fprintf(&b, "Servings: %d\n", recipe.Servings)
+	}
+	_, _ = fmt.Fprintf(&b, "Prep: %s  Cook: %s  Total: %s\n\n",
+		readableDuration(recipe.PerpTime), readableDuration(recipe.CookTime), readableDuration(recipe.TotalTime))
+
+	// 食材
+	b.WriteString("INGREDIENTS\n")
+	for _, ingredient := range recipe.Ingredients {
+		_, _ = fmt.Fprintf(&b, "  * %s\n", ingredient)
+	}
+
+	// 步骤
+	b.WriteString("\nINSTRUCTIONS\n")
+	for i, step := range recipe.Instruction {
+		_, _ = fmt.Fprintf(&b, "  %d. %s\n", i+1, step)
+	}
+
+	// 营养成分
+	b.WriteString("\nNUTRITION\n")
+	for _, n := range nutritionRows(recipe) {
+		_, _ = fmt.Fprintf(&b, "  %-14s %s\n", n[0], n[1])
+	}
+
+	return b.String()
+}
+
+// markdownEscaper 转义Markdown的行内特殊字符
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `#`, `\#`,
+	`<`, `\<`, `>`, `\>`, `|`, `\|`, `!`, `\!`, `~`, `\~`,
+)
+
+// orderedListRegex 匹配行首会被识别为有序列表的编号
+var orderedListRegex = regexp.MustCompile(`^(\d+)([.)])`)
+
+// escapeMarkdown 转义用户填写的单行文本，避免破坏导出的Markdown文档结构
+func escapeMarkdown(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = markdownEscaper.Replace(s)
+
+	// 行首的 -、+、= 和 1. 会被识别为列表或标题
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") || strings.HasPrefix(s, "=") {
+		return `\` + s
+	}
+	return orderedListRegex.ReplaceAllString(s, `$1\$2`)
+}
+
+// nutritionRows 获取营养成分的名称和带单位的数值
+func nutritionRows(recipe *model.Recipe) [][2]string {
+	return [][2]string{
+		{"Calories", formatNumber(recipe.Calories) + " kcal"},
+		{"Fat", formatNumber(recipe.Fat) + " g"},
+		{"Saturated fat", formatNumber(recipe.SaturatedFat) + " g"},
+		{"Sodium", formatNumber(recipe.Sodium) + " mg"},
+		{"Carbohydrate", formatNumber(recipe.Carbohydrate) + " g"},
+		{"Fiber", formatNumber(recipe.Fiber) + " g"},
+		{"Sugar", formatNumber(recipe.Sugar) + " g"},
+		{"Protein", formatNumber(recipe.Protein) + " g"},
+	}
+}
+
+// isoDuration 将秒数转换为ISO-8601格式的时间段，为0或者超过time.Duration的范围时返回空字符串
+func isoDuration(seconds int64) string {
+	if seconds <= 0 || seconds > math.MaxInt64/int64(time.Second) {
+		return ""
+	}
+	return duration.FormatISO8601(time.Duration(seconds) * time.Second)
+}
+
+// readableDuration 将秒数转换为便于阅读的时间，例如1 h 5 min
+func readableDuration(seconds int64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	h, m := seconds/3600, (seconds%3600+59)/60
+	if m == 60 {
+		h, m = h+1, 0
+	}
+	switch {
+	case h > 0 && m > 0:
+		return fmt.Sprintf("%d h %d min", h, m)
+	case h > 0:
+		return fmt.Sprintf("%d h", h)
+	default:
+		return fmt.Sprintf("%d min", m)
+	}
+}
+
+// formatNumber 格式化数值，最多保留一位小数
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(float64(int64(v*10+0.5))/10, 'f', -1, 64)
+}
@@ -0,0 +1,53 @@
+package recipe
+
+import (
+	"main/app/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"Pancakes", "Pancakes"},
+		{"# Best *ever* [pancakes]", `\# Best \*ever\* \[pancakes\]`},
+		{"- 2 eggs", `\- 2 eggs`},
+		{"+ salt", `\+ salt`},
+		{"1. Mix", `1\. Mix`},
+		{"10) Bake", `10\) Bake`},
+		{"Bake 2. Serve", "Bake 2. Serve"},
+		{"a_b | c > d", `a\_b \| c \> d`},
+		{`C:\path`, `C:\\path`},
+		{"  Mix\n- well  ", `Mix - well`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeMarkdown(tt.s); got != tt.want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	s := &SExport{}
+	got := s.ToMarkdown(&model.Recipe{
+		Name:        "# Pancakes",
+		Description: "Fluffy\n# not a heading",
+		Ingredients: []string{"- 2 eggs", "1. cup *flour*"},
+		Instruction: []string{"Mix\n## well"},
+	})
+
+	for _, want := range []string{
+		"# \\# Pancakes\n",
+		"> Fluffy\n> \\# not a heading\n",
+		"- \\- 2 eggs\n",
+		"- 1\\. cup \\*flour\\*\n",
+		"1. Mix \\#\\# well\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToMarkdown() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cast"
+	"go.mongodb.org/mongo-driver/bson"
+	g "main/app/global"
+	"main/app/internal/model"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// FacetBuckets 定义按区间统计的字段和区间的边界，时间的单位为秒，热量的单位为千卡
+var FacetBuckets = map[string][]float64{
+	"cook_time":  {0, 900, 1800, 3600, 7200},
+	"perp_time":  {0, 900, 1800, 3600, 7200},
+	"total_time": {0, 900, 1800, 3600, 7200},
+	"calories":   {0, 200, 400, 600, 800},
+}
+
+// facetValues 定义按值统计的字段和返回的最大数量
+var facetValues = map[string]int{
+	"category": 50,
+	"dietary":  50,
+}
+
+// facetDefault 定义默认区间的名称，字段为空、不是数字或者小于最小边界的菜谱放到这个区间中
+const facetDefault = "other"
+
+// CheckFacet 检查给定的统计字段是否有效
+func (s *SSearch) CheckFacet(name string) bool {
+	_, isBucket := FacetBuckets[name]
+	_, isValue := facetValues[name]
+	return isBucket || isValue
+}
+
+// GetFacets 使用同一个过滤器统计每个字段的分布
+func (s *SSearch) GetFacets(ctx context.Context, filter bson.D, names []string) (map[string][]*model.RecipeFacet, error) {
+	// 为每一个字段创建一个统计的管道
+	facet := bson.D{}
+	for _, name := range names {
+		if boundaries, ok := FacetBuckets[name]; ok {
+			// 按区间统计，最后加上无穷大的边界，使超出最大边界的菜谱有单独的区间，不和默认区间混在一起
+			facet = append(facet, bson.E{Key: name, Value: bson.A{
+				bson.D{{Key: "$bucket", Value: bson.D{
+					{Key: "groupBy", Value: "$" + name},
+					{Key: "boundaries", Value: append(append([]float64{}, boundaries...), math.Inf(1))},
+					{Key: "default", Value: facetDefault},
+					{Key: "output", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}},
+				}}},
+			}})
+		} else if limit, ok := facetValues[name]; ok {
+			// 按值统计，数组字段需要先展开
+			pipeline := bson.A{
+				bson.D{{Key: "$unwind", Value: "$" + name}},
+				bson.D{{Key: "$sortByCount", Value: "$" + name}},
+				bson.D{{Key: "$limit", Value: limit}},
+			}
+			facet = append(facet, bson.E{Key: name, Value: pipeline})
+		} else {
+			return nil, fmt.Errorf(`invalid param "facets"`)
+		}
+	}
+
+	// 先使用过滤器匹配菜谱，再统计每个字段的分布
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$facet", Value: facet}},
+	}
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		g.Logger.Errorf("aggregate [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 聚合的结果只有一个文档，每个字段对应一个统计的列表
+	var docs []map[string][]bson.M
+	err = cur.All(ctx, &docs)
+	if err != nil {
+		g.Logger.Errorf("decode [recipe] facet failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将统计的结果转换为统计的对象
+	facets := make(map[string][]*model.RecipeFacet)
+	for _, name := range names {
+		facets[name] = []*model.RecipeFacet{}
+		if len(docs) == 0 {
+			continue
+		}
+		for _, bucket := range docs[0][name] {
+			facets[name] = append(facets[name], toFacet(name, bucket))
+		}
+	}
+
+	return facets, nil
+}
+
+// toFacet 将一条统计结果转换为统计的对象
+func toFacet(name string, bucket bson.M) *model.RecipeFacet {
+	facet := &model.RecipeFacet{Count: cast.ToInt64(bucket["count"])}
+
+	// 按值统计时，_id就是字段的值
+	boundaries, ok := FacetBuckets[name]
+	if !ok {
+		facet.Value = fmt.Sprint(bucket["_id"])
+		return facet
+	}
+
+	// 按区间统计时，_id是区间的下界或者默认区间的名称
+	if bucket["_id"] == facetDefault {
+		facet.Value = facetDefault
+		return facet
+	}
+	min := cast.ToFloat64(bucket["_id"])
+	i := sort.SearchFloat64s(boundaries, min)
+	// 最大边界以上的区间没有上界
+	if i+1 >= len(boundaries) {
+		facet.Value = fmt.Sprintf("%s+", strconv.FormatFloat(min, 'f', -1, 64))
+		facet.Min = &min
+		return facet
+	}
+	max := boundaries[i+1]
+	facet.Value = fmt.Sprintf("%s-%s", strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64))
+	facet.Min, facet.Max = &min, &max
+	return facet
+}
@@ -0,0 +1,304 @@
+package recipe
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/utils/storage"
+	"main/utils/thumbnail"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SImage 定义一个菜谱图片的结构体
+type SImage struct {
+	once    sync.Once
+	storage storage.Storage
+}
+
+// ImageTypes 定义允许上传的图片类型和对应的扩展名
+var ImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Storage 获取保存图片的存储，第一次调用时根据配置创建本地存储
+func (s *SImage) Storage() storage.Storage {
+	s.once.Do(func() {
+		prefixUrl := ""
+		if g.Config.App != nil {
+			prefixUrl = g.Config.App.PrefixUrl
+		}
+
+		s.storage = storage.NewLocal(&storage.LocalConfig{
+			Root:      g.Config.Upload.GetPath(),
+			UrlPrefix: g.Config.Upload.GetUrlPrefix(prefixUrl),
+		})
+	})
+
+	return s.storage
+}
+
+// ReadImage 读取上传的图片，根据内容判断图片的类型，不信任客户端提供的类型
+func (s *SImage) ReadImage(r io.Reader) ([]byte, string, error) {
+	maxSize := g.Config.Upload.GetMaxSize()
+
+	// 多读取一个字节，用于判断图片是否超过大小限制
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		g.Logger.Errorf("read image failed. err: %v", err)
+		return nil, "", fmt.Errorf("invalid image")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("image too large")
+	}
+
+	contentType := http.DetectContentType(data)
+	if _, ok := ImageTypes[contentType]; !ok {
+		return nil, "", fmt.Errorf("unsupported image type")
+	}
+
+	return data, contentType, nil
+}
+
+// SaveImage 保存菜谱的原图，并生成各个尺寸的缩略图
+// 原图保存为recipes/{菜谱ID}/{随机名称}.{扩展名}，缩略图保存为recipes/{菜谱ID}/{随机名称}_{宽度}.jpg
+func (s *SImage) SaveImage(ctx context.Context, recipeId int64, data []byte, contentType string) (*model.RecipeImage, error) {
+	// 先只读取图片的尺寸，避免解码尺寸过大的图片
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("invalid image")
+	}
+	if int64(config.Width)*int64(config.Height) > g.Config.Upload.GetMaxPixels() {
+		return nil, fmt.Errorf("image dimensions too large")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image")
+	}
+
+	name, err := randomName()
+	if err != nil {
+		g.Logger.Errorf("generate image name failed. err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+	base := fmt.Sprintf("recipes/%d/%s", recipeId, name)
+
+	img := &model.RecipeImage{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		Thumbnails:  make(map[int]string),
+	}
+	var keys []string
+
+	// 保存原图
+	key := base + ImageTypes[contentType]
+	img.Url, err = s.Storage().Save(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		g.Logger.Errorf("save image failed. err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+	keys = append(keys, key)
+
+	// 生成并保存缩略图，保存失败时删除已经保存的文件
+	// 原图只绘制到白色背景上一次，各个尺寸的缩略图共用，避免为每个尺寸复制一份原图
+	flat := thumbnail.Flatten(src)
+	for _, width := range g.Config.Upload.GetThumbnailSizes() {
+		if width <= 0 {
+			continue
+		}
+
+		buf := &bytes.Buffer{}
+		if err = jpeg.Encode(buf, thumbnail.Resize(flat, width), &jpeg.Options{Quality: 85}); err == nil {
+			key = thumbnailKey(base, width)
+			img.Thumbnails[width], err = s.Storage().Save(ctx, key, buf)
+		}
+		if err != nil {
+			g.Logger.Errorf("save thumbnail failed. err: %v", err)
+			s.deleteKeys(ctx, keys)
+			return nil, fmt.Errorf("internal err")
+		}
+		keys = append(keys, key)
+	}
+
+	return img, nil
+}
+
+// DeleteImage 删除保存的原图和缩略图，失败时只记录日志
+func (s *SImage) DeleteImage(ctx context.Context, img *model.RecipeImage) {
+	urls := []string{img.Url}
+	for _, u := range img.Thumbnails {
+		urls = append(urls, u)
+	}
+
+	keys := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if key, ok := s.keyOf(u); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	s.deleteKeys(ctx, keys)
+}
+
+// AddImages 将图片的URL追加到菜谱的图片中，并保存新的版本，追加后超过图片数量的限制时返回错误
+func (s *SImage) AddImages(ctx context.Context, recipe *model.Recipe, images []*model.RecipeImage) error {
+	urls := make([]string, 0, len(images))
+	for _, img := range images {
+		urls = append(urls, img.Url)
+	}
+	if len(urls) > MaxImages {
+		return fmt.Errorf("too many images")
+	}
+
+	// 修改前保证当前版本有快照
+	if err := insVersion.EnsureVersion(ctx, recipe); err != nil {
+		return err
+	}
+
+	// 只有菜谱的图片数量加上新的图片不超过限制时才更新，避免并发上传超过限制
+	err := g.MongoDB.Database("food").Collection("recipe").
+		FindOneAndUpdate(ctx,
+			bson.D{
+				{Key: "recipe_id", Value: recipe.RecipeId},
+				{Key: fmt.Sprintf("images.%d", MaxImages-len(urls)), Value: bson.D{{Key: "$exists", Value: false}}},
+			},
+			bson.D{
+				{Key: "$push", Value: bson.D{{Key: "images", Value: bson.D{{Key: "$each", Value: urls}}}}},
+				{Key: "$set", Value: bson.D{{Key: "update_time", Value: time.Now()}}},
+				{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(recipe)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("too many images")
+		}
+		g.Logger.Errorf("add recipe images failed. err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	// 保存版本失败时只记录日志，下次修改前会补充这个版本的快照
+	_ = insVersion.RecordVersion(ctx, recipe, ActionImages)
+
+	return nil
+}
+
+// ThumbnailUrls 根据上传的图片的URL推导各个宽度的缩略图的URL，不是上传到这个存储的图片时返回nil
+func (s *SImage) ThumbnailUrls(u string) map[int]string {
+	key, ok := s.keyOf(u)
+	if !ok {
+		return nil
+	}
+
+	base := strings.TrimSuffix(key, path.Ext(key))
+	urls := make(map[int]string)
+	for _, width := range g.Config.Upload.GetThumbnailSizes() {
+		if width > 0 {
+			urls[width] = s.Storage().URL(thumbnailKey(base, width))
+		}
+	}
+
+	return urls
+}
+
+// DeleteRecipeImages 删除已删除的菜谱的原图和缩略图，仍被其它菜谱或版本引用的图片不删除（例如复制的菜谱），失败时只记录日志
+func (s *SImage) DeleteRecipeImages(ctx context.Context, recipeId int64, urls []string) {
+	db := g.MongoDB.Database("food")
+	var keys []string
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		key, ok := s.keyOf(u)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// 检查其它菜谱和版本是否还引用这张图片
+		referenced := false
+		for _, ref := range []struct{ collection, field string }{
+			{"recipe", "images"},
+			{"recipe_version", "recipe.images"},
+		} {
+			cnt, err := db.Collection(ref.collection).CountDocuments(ctx, bson.D{
+				{Key: ref.field, Value: u},
+				{Key: "recipe_id", Value: bson.D{{Key: "$ne", Value: recipeId}}},
+			}, options.Count().SetLimit(1))
+			if err != nil {
+				g.Logger.Errorf("count [%s] document failed, err: %v", ref.collection, err)
+			}
+			if err != nil || cnt > 0 {
+				referenced = true
+				break
+			}
+		}
+		if referenced {
+			continue
+		}
+
+		// 删除原图和各个尺寸的缩略图
+		keys = append(keys, key)
+		base := strings.TrimSuffix(key, path.Ext(key))
+		for _, width := range g.Config.Upload.GetThumbnailSizes() {
+			if width > 0 {
+				keys = append(keys, thumbnailKey(base, width))
+			}
+		}
+	}
+
+	s.deleteKeys(ctx, keys)
+}
+
+// deleteKeys 删除存储中的文件，失败时只记录日志
+func (s *SImage) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.Storage().Delete(ctx, key); err != nil {
+			g.Logger.Errorf("delete image failed. key: %s, err: %v", key, err)
+		}
+	}
+}
+
+// keyOf 根据图片的URL获取存储中的key，不是这个存储的URL时返回false
+func (s *SImage) keyOf(u string) (string, bool) {
+	prefix := strings.TrimSuffix(s.Storage().URL(""), "/") + "/"
+	if !strings.HasPrefix(u, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(u, prefix), true
+}
+
+// thumbnailKey 获取原图对应的指定宽度的缩略图的key，base为原图去掉扩展名的key
+// 缩略图不单独保存到菜谱中，读取和删除时都通过这个规则推导
+func thumbnailKey(base string, width int) string {
+	return fmt.Sprintf("%s_%d.jpg", base, width)
+}
+
+// randomName 生成一个随机的文件名
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
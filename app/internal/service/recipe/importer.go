@@ -0,0 +1,498 @@
+package recipe
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/utils/duration"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SImport 定义一个导入菜谱的结构体
+type SImport struct{}
+
+// importBatchSize 每次批量写入数据库的菜谱数量
+const importBatchSize = 500
+
+// columnAliases 定义数据集中常见的列名和菜谱字段的对应关系
+// 同一个字段有多个列时，和字段同名的列优先，其次按这里的顺序，排在前面的优先
+var columnAliases = [][2]string{
+	{"id", "recipe_id"},
+	{"recipeid", "recipe_id"},
+	{"title", "name"},
+	{"recipe_name", "name"},
+	{"image", "images"},
+	{"recipecategory", "category"},
+	{"recipe_category", "category"},
+	{"keyword", "keywords"},
+	{"instructions", "instruction"},
+	{"recipeinstructions", "instruction"},
+	{"steps", "instruction"},
+	{"ingredient", "ingredients"},
+	{"recipeingredientparts", "ingredients"},
+	{"recipe_servings", "servings"},
+	{"recipeservings", "servings"},
+	{"yield", "servings"},
+	{"recipeyield", "servings"},
+	{"cooktime", "cook_time"},
+	{"prep_time", "perp_time"},
+	{"preptime", "perp_time"},
+	{"totaltime", "total_time"},
+	{"fatcontent", "fat"},
+	{"saturatedfatcontent", "saturated_fat"},
+	{"sodiumcontent", "sodium"},
+	{"carbohydratecontent", "carbohydrate"},
+	{"carbohydrates", "carbohydrate"},
+	{"fibercontent", "fiber"},
+	{"sugarcontent", "sugar"},
+	{"proteincontent", "protein"},
+}
+
+// maxImportServings 定义导入的菜谱的份数的上限
+const maxImportServings = 1000
+
+// leadingNumberRegexp 匹配开头的数字，例如"1 loaf"和"4-6 servings"中的1和4
+var leadingNumberRegexp = regexp.MustCompile(`^\d+(?:\.\d+)?`)
+
+// ParseJSONL 解析JSONL格式的菜谱，每一行是一个JSON对象
+func (s *SImport) ParseJSONL(r io.Reader) ([]*model.Recipe, []*model.RecipeImportError) {
+	var recipes []*model.Recipe
+	var errs []*model.RecipeImportError
+
+	// 逐行读取，单行最大16MB
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		// 跳过空行
+		if text == "" {
+			continue
+		}
+
+		// 将一行解析为JSON对象
+		row := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			errs = append(errs, &model.RecipeImportError{Line: line, Msg: "invalid json"})
+			continue
+		}
+
+		// 将JSON对象的值转换为字符串或字符串的列表
+		values := make(map[string]interface{})
+		for key, value := range row {
+			switch v := value.(type) {
+			case []interface{}:
+				list := make([]string, 0, len(v))
+				for _, item := range v {
+					list = append(list, fmt.Sprint(item))
+				}
+				values[key] = list
+			case nil:
+			case float64:
+				values[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				values[key] = fmt.Sprint(v)
+			}
+		}
+
+		// 将值映射到菜谱的字段
+		recipe, err := mapRecipe(values)
+		if err != nil {
+			errs = append(errs, &model.RecipeImportError{Line: line, Msg: err.Error()})
+			continue
+		}
+		recipes = append(recipes, recipe)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, &model.RecipeImportError{Line: line + 1, Msg: err.Error()})
+	}
+
+	return recipes, errs
+}
+
+// ParseCSV 解析CSV格式的菜谱，第一行是列名
+// 列表字段可以是JSON数组，也可以使用"|"分隔
+func (s *SImport) ParseCSV(r io.Reader) ([]*model.Recipe, []*model.RecipeImportError) {
+	var recipes []*model.Recipe
+	var errs []*model.RecipeImportError
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	// 读取列名
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []*model.RecipeImportError{{Line: 1, Msg: "invalid header"}}
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			errs = append(errs, &model.RecipeImportError{Line: line, Msg: err.Error()})
+			continue
+		}
+		if len(record) != len(header) {
+			errs = append(errs, &model.RecipeImportError{Line: line, Msg: "wrong number of fields"})
+			continue
+		}
+
+		// 按列名将一行转换为值
+		values := make(map[string]interface{})
+		for i, column := range header {
+			values[column] = record[i]
+		}
+
+		// 将值映射到菜谱的字段
+		recipe, err := mapRecipe(values)
+		if err != nil {
+			errs = append(errs, &model.RecipeImportError{Line: line, Msg: err.Error()})
+			continue
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	return recipes, errs
+}
+
+// mapRecipe 将一行的值映射到菜谱的字段，并检查菜谱是否有效
+func mapRecipe(values map[string]interface{}) (*model.Recipe, error) {
+	// 规范化列名，并按字段和优先级排序，使多个列对应同一个字段时结果是确定的
+	type mappedColumn struct {
+		column string
+		field  string
+		rank   int
+	}
+	columns := make([]*mappedColumn, 0, len(values))
+	for column := range values {
+		field, rank := resolveColumn(column)
+		columns = append(columns, &mappedColumn{column: column, field: field, rank: rank})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if a.field != b.field {
+			return a.field < b.field
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		return a.column < b.column
+	})
+
+	recipe := &model.Recipe{}
+	done := make(map[string]bool)
+	for _, c := range columns {
+		// 同一个字段只使用优先级最高的非空的列
+		if done[c.field] {
+			continue
+		}
+		value := values[c.column]
+
+		// 列表字段
+		if list, ok := value.([]string); ok {
+			if len(list) == 0 {
+				continue
+			}
+			if err := setListField(recipe, c.field, list); err != nil {
+				return nil, err
+			}
+			done[c.field] = true
+			continue
+		}
+
+		// 单值字段
+		str := strings.TrimSpace(fmt.Sprint(value))
+		if str == "" {
+			continue
+		}
+		if err := setField(recipe, c.field, str); err != nil {
+			return nil, err
+		}
+		done[c.field] = true
+	}
+
+	// 检查必填的字段
+	if recipe.RecipeId <= 0 {
+		return nil, fmt.Errorf("recipe_id is required")
+	}
+	if recipe.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(recipe.Ingredients) == 0 {
+		return nil, fmt.Errorf("ingredients is required")
+	}
+	// 总时间为空时使用烹饪时间和准备时间之和
+	if recipe.TotalTime == 0 {
+		recipe.TotalTime = recipe.CookTime + recipe.PerpTime
+	}
+
+	return recipe, nil
+}
+
+// resolveColumn 获取列名对应的菜谱字段和优先级，优先级的数值越小越优先
+func resolveColumn(column string) (string, int) {
+	field := strings.ToLower(strings.TrimSpace(column))
+	field = strings.ReplaceAll(field, " ", "_")
+	for i, alias := range columnAliases {
+		if alias[0] == field {
+			return alias[1], i + 1
+		}
+	}
+
+	return field, 0
+}
+
+// setListField 设置菜谱的列表字段
+func setListField(recipe *model.Recipe, field string, list []string) error {
+	// 去掉空白项
+	var res []string
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+
+	switch field {
+	case "images":
+		recipe.Images = res
+	case "dietary":
+		recipe.Dietary = res
+	case "keywords":
+		recipe.Keywords = res
+	case "instruction":
+		recipe.Instruction = res
+	case "ingredients":
+		recipe.Ingredients = res
+	default:
+		// 单值字段使用第一项
+		if len(res) > 0 {
+			return setField(recipe, field, res[0])
+		}
+	}
+
+	return nil
+}
+
+// setField 设置菜谱的单值字段，未知的列会被忽略
+func setField(recipe *model.Recipe, field, value string) error {
+	switch field {
+	case "recipe_id":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid recipe_id")
+		}
+		recipe.RecipeId = v
+	case "name":
+		recipe.Name = value
+	case "category":
+		recipe.Category = value
+	case "description":
+		recipe.Description = value
+	case "images", "dietary", "keywords", "instruction", "ingredients":
+		return setListField(recipe, field, splitList(value))
+	case "servings":
+		// 份数可以是文本，例如"1 loaf"，只使用开头的数字，没有数字时份数为空
+		number := leadingNumberRegexp.FindString(value)
+		if number == "" {
+			return nil
+		}
+		v, err := strconv.ParseFloat(number, 64)
+		if err != nil || v > maxImportServings {
+			return fmt.Errorf("invalid servings")
+		}
+		recipe.Servings = int64(math.Round(v))
+	case "cook_time", "perp_time", "total_time":
+		d, err := duration.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s", field)
+		}
+		seconds := int64(d / time.Second)
+		switch field {
+		case "cook_time":
+			recipe.CookTime = seconds
+		case "perp_time":
+			recipe.PerpTime = seconds
+		case "total_time":
+			recipe.TotalTime = seconds
+		}
+	case "calories", "fat", "saturated_fat", "sodium", "carbohydrate", "fiber", "sugar", "protein":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || !ValidNutrition(v) {
+			return fmt.Errorf("invalid %s", field)
+		}
+		*nutritionField(recipe, field) = v
+	case "visibility":
+		if value != VisibilityPublic && value != VisibilityPrivate {
+			return fmt.Errorf("invalid visibility")
+		}
+		recipe.Visibility = value
+	}
+
+	return nil
+}
+
+// nutritionField 获取菜谱中营养成分字段的指针
+func nutritionField(recipe *model.Recipe, field string) *float64 {
+	switch field {
+	case "calories":
+		return &recipe.Calories
+	case "fat":
+		return &recipe.Fat
+	case "saturated_fat":
+		return &recipe.SaturatedFat
+	case "sodium":
+		return &recipe.Sodium
+	case "carbohydrate":
+		return &recipe.Carbohydrate
+	case "fiber":
+		return &recipe.Fiber
+	case "sugar":
+		return &recipe.Sugar
+	case "protein":
+		return &recipe.Protein
+	}
+
+	return nil
+}
+
+// splitList 拆分列表字段，支持JSON数组和"|"分隔
+func splitList(value string) []string {
+	if strings.HasPrefix(value, "[") {
+		var list []string
+		if err := json.Unmarshal([]byte(value), &list); err == nil {
+			return list
+		}
+	}
+
+	return strings.Split(value, "|")
+}
+
+// UpsertRecipes 按菜谱ID批量写入菜谱，已存在的菜谱会被更新，用户创建的菜谱不会被覆盖
+// 返回匹配的数量、修改的数量、新增的数量和写入失败的菜谱
+func (s *SImport) UpsertRecipes(ctx context.Context, recipes []*model.Recipe) (int64, int64, int64, []*model.RecipeImportError, error) {
+	var matched, modified, upserted int64
+	var errs []*model.RecipeImportError
+	collection := g.MongoDB.Database("food").Collection("recipe")
+
+	now := time.Now()
+	for begin := 0; begin < len(recipes); begin += importBatchSize {
+		end := begin + importBatchSize
+		if end > len(recipes) {
+			end = len(recipes)
+		}
+
+		// 为每一个菜谱创建一个更新的操作
+		var models []mongo.WriteModel
+		for _, recipe := range recipes[begin:end] {
+			visibility := recipe.Visibility
+			if visibility == "" {
+				visibility = VisibilityPublic
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.D{
+					{Key: "recipe_id", Value: recipe.RecipeId},
+					{Key: "owner_id", Value: bson.D{{Key: "$in", Value: bson.A{0, nil}}}},
+				}).
+				SetUpdate(bson.D{
+					{Key: "$set", Value: bson.D{
+						{Key: "images", Value: recipe.Images},
+						{Key: "name", Value: recipe.Name},
+						{Key: "category", Value: recipe.Category},
+						{Key: "dietary", Value: recipe.Dietary},
+						{Key: "description", Value: recipe.Description},
+						{Key: "keywords", Value: recipe.Keywords},
+						{Key: "instruction", Value: recipe.Instruction},
+						{Key: "ingredients", Value: recipe.Ingredients},
+						{Key: "servings", Value: recipe.Servings},
+						{Key: "cook_time", Value: recipe.CookTime},
+						{Key: "perp_time", Value: recipe.PerpTime},
+						{Key: "total_time", Value: recipe.TotalTime},
+						{Key: "calories", Value: recipe.Calories},
+						{Key: "fat", Value: recipe.Fat},
+						{Key: "saturated_fat", Value: recipe.SaturatedFat},
+						{Key: "sodium", Value: recipe.Sodium},
+						{Key: "carbohydrate", Value: recipe.Carbohydrate},
+						{Key: "fiber", Value: recipe.Fiber},
+						{Key: "sugar", Value: recipe.Sugar},
+						{Key: "protein", Value: recipe.Protein},
+						{Key: "visibility", Value: visibility},
+						{Key: "update_time", Value: now},
+					}},
+					// 只在新增时设置的字段
+					{Key: "$setOnInsert", Value: bson.D{
+						{Key: "collect_count", Value: 0},
+						{Key: "rating_avg", Value: 0},
+						{Key: "rating_count", Value: 0},
+						{Key: "owner_id", Value: 0},
+						{Key: "parent_id", Value: 0},
+						{Key: "version", Value: 1},
+						{Key: "create_time", Value: now},
+					}},
+				}).
+				SetUpsert(true))
+		}
+
+		// 批量写入，单个菜谱失败不影响其他菜谱
+		res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if res != nil {
+			matched += res.MatchedCount
+			modified += res.ModifiedCount
+			upserted += res.UpsertedCount
+		}
+		if err != nil {
+			// 如果是部分菜谱写入失败，例如菜谱ID已经被用户的菜谱占用，记录失败的菜谱
+			var bulkErr mongo.BulkWriteException
+			if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+				g.Logger.Errorf("bulk write [recipe] document failed, err: %v", err)
+				return matched, modified, upserted, errs, fmt.Errorf("internal err")
+			}
+			for _, writeErr := range bulkErr.WriteErrors {
+				msg := writeErr.Message
+				if mongo.IsDuplicateKeyError(writeErr) {
+					msg = "recipe_id is owned by a user recipe"
+				}
+				errs = append(errs, &model.RecipeImportError{
+					RecipeId: recipes[begin+writeErr.Index].RecipeId,
+					Msg:      msg,
+				})
+			}
+		}
+	}
+
+	// 导入的菜谱ID可能大于计数器的值，更新计数器避免分配重复的ID
+	var maxId int64
+	for _, recipe := range recipes {
+		if recipe.RecipeId > maxId {
+			maxId = recipe.RecipeId
+		}
+	}
+	_, err := g.MongoDB.Database("food").Collection("counter").UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: "recipe_id"}},
+		bson.D{{Key: "$max", Value: bson.D{{Key: "seq", Value: maxId}}}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		g.Logger.Errorf("update [counter] document failed, err: %v", err)
+		return matched, modified, upserted, errs, fmt.Errorf("internal err")
+	}
+
+	return matched, modified, upserted, errs, nil
+}
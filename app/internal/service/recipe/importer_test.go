@@ -0,0 +1,59 @@
+package recipe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		csv         string
+		servings    int64
+		instruction string
+		calories    float64
+		err         string
+	}{
+		{"servings before yield", "id,name,ingredients,yield,RecipeServings,steps,instructions\n" +
+			"1,Bread,flour|water,1 loaf,8,knead,mix|bake\n", 8, "mix", 0, ""},
+		{"text yield", "id,name,ingredients,recipeYield\n1,Bread,flour,1 loaf\n", 1, "", 0, ""},
+		{"range yield", "id,name,ingredients,yield\n1,Bread,flour,4-6 servings\n", 4, "", 0, ""},
+		{"yield without number", "id,name,ingredients,yield\n1,Bread,flour,one loaf\n", 0, "", 0, ""},
+		{"empty column skipped", "id,name,ingredients,servings,yield\n1,Bread,flour,,2\n", 2, "", 0, ""},
+		{"same name first", "id,name,ingredients,instruction,instructions\n1,Bread,flour,knead,mix\n", 0, "knead", 0, ""},
+		{"nutrition", "id,name,ingredients,calories\n1,Bread,flour,250.5\n", 0, "", 250.5, ""},
+		{"NaN servings", "id,name,ingredients,servings\n1,Bread,flour,NaN\n", 0, "", 0, ""},
+		{"too many servings", "id,name,ingredients,servings\n1,Bread,flour,5000\n", 0, "", 0, "invalid servings"},
+		{"NaN calories", "id,name,ingredients,calories\n1,Bread,flour,NaN\n", 0, "", 0, "invalid calories"},
+		{"Inf protein", "id,name,ingredients,ProteinContent\n1,Bread,flour,+Inf\n", 0, "", 0, "invalid protein"},
+	}
+
+	s := &SImport{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 多次解析，结果不能依赖map的遍历顺序
+			for i := 0; i < 20; i++ {
+				recipes, errs := s.ParseCSV(strings.NewReader(tt.csv))
+				if tt.err != "" {
+					if len(errs) != 1 || errs[0].Msg != tt.err {
+						t.Fatalf("errs = %v, want %q", errs, tt.err)
+					}
+					continue
+				}
+				if len(errs) != 0 || len(recipes) != 1 {
+					t.Fatalf("recipes = %d, errs = %v", len(recipes), errs)
+				}
+				recipe := recipes[0]
+				if recipe.Servings != tt.servings {
+					t.Fatalf("servings = %d, want %d", recipe.Servings, tt.servings)
+				}
+				if tt.instruction != "" && (len(recipe.Instruction) == 0 || recipe.Instruction[0] != tt.instruction) {
+					t.Fatalf("instruction = %q, want %q first", recipe.Instruction, tt.instruction)
+				}
+				if recipe.Calories != tt.calories {
+					t.Fatalf("calories = %v, want %v", recipe.Calories, tt.calories)
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package recipe
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	g "main/app/global"
 	"main/app/internal/model"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,32 +16,56 @@ import (
 // SInfo 定义一个菜谱信息的结构体
 type SInfo struct{}
 
+// NutritionFields 定义可以按范围过滤的营养成分字段
+var NutritionFields = []string{
+	"calories",
+	"fat",
+	"saturated_fat",
+	"sodium",
+	"carbohydrate",
+	"fiber",
+	"sugar",
+	"protein",
+}
+
 // GetRecipeById 根据ID从数据库中获取菜谱
 func (s *SInfo) GetRecipeById(ctx context.Context, recipeId int64) *model.Recipe {
-	// 定义一个过滤器，用于在数据库中查找匹配的菜谱
-	filter := bson.D{
-		{
-			"recipe_id",
-			recipeId,
-		},
+	// 查找菜谱，出现任何错误都返回nil
+	recipe, err := s.GetRecipe(ctx, recipeId)
+	if err != nil {
+		return nil
 	}
 
+	// 返回菜谱的对象
+	return recipe
+}
+
+// GetRecipe 根据ID从数据库中获取菜谱，并区分菜谱不存在和内部错误
+func (s *SInfo) GetRecipe(ctx context.Context, recipeId int64) (*model.Recipe, error) {
+	// 定义一个过滤器，用于在数据库中查找匹配的菜谱
+	filter := bson.D{{Key: "recipe_id", Value: recipeId}}
+
 	// 创建一个菜谱的对象
 	var elem model.Recipe
 
-	// 在数据库中查找匹配的菜谱
-	cur := g.MongoDB.Database("food").Collection("recipe").
-		FindOne(ctx, filter)
-
-	// 将查找到的菜谱解码为菜谱的对象
-	err := cur.Decode(&elem)
-	// 如果解码过程中出现错误，返回nil
+	// 在数据库中查找匹配的菜谱，并将其解码为菜谱的对象
+	err := g.MongoDB.Database("food").Collection("recipe").
+		FindOne(ctx, filter).
+		Decode(&elem)
+	// 如果查找过程中出现错误
 	if err != nil {
-		return nil
+		// 如果错误是因为找不到文档，返回菜谱不存在的错误
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("recipe not found")
+		}
+		// 记录错误日志
+		g.Logger.Errorf("find [recipe] document failed, err: %v", err)
+		// 返回内部错误
+		return nil, fmt.Errorf("internal err")
 	}
 
 	// 返回菜谱的对象
-	return &elem
+	return &elem, nil
 }
 
 // GetTimeDuration 获取时间段
@@ -58,3 +86,103 @@ func (s *SInfo) GetTimeDuration(timeStr string) (time.Duration, time.Duration) {
 	// 返回两个时间段
 	return from, to
 }
+
+// GetNutritionRange 获取营养成分的范围过滤条件
+// rangeStr的格式为"200-600"，任意一端可以为空；minStr和maxStr会覆盖rangeStr中对应的一端
+func (s *SInfo) GetNutritionRange(rangeStr, minStr, maxStr string) (bson.D, error) {
+	// 定义最小值和最大值的字符串
+	var from, to string
+
+	// 如果范围字符串不为空，将其按照"-"分割为两部分
+	if rangeStr != "" {
+		output := strings.Split(rangeStr, "-")
+		// 如果分割后的长度不为2，返回错误
+		if len(output) != 2 {
+			return nil, fmt.Errorf("invalid range")
+		}
+		from, to = output[0], output[1]
+	}
+	// 如果单独指定了最小值或最大值，使用单独指定的值
+	if minStr != "" {
+		from = minStr
+	}
+	if maxStr != "" {
+		to = maxStr
+	}
+
+	// 定义一个过滤条件
+	cond := bson.D{}
+
+	// 解析最小值
+	var min float64
+	if from != "" {
+		v, err := strconv.ParseFloat(strings.TrimSpace(from), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid range")
+		}
+		min = v
+		cond = append(cond, bson.E{Key: "$gte", Value: v})
+	}
+	// 解析最大值
+	if to != "" {
+		v, err := strconv.ParseFloat(strings.TrimSpace(to), 64)
+		if err != nil || v < 0 || (from != "" && v < min) {
+			return nil, fmt.Errorf("invalid range")
+		}
+		cond = append(cond, bson.E{Key: "$lte", Value: v})
+	}
+
+	// 返回过滤条件，如果没有指定范围则为空
+	return cond, nil
+}
+
+// CheckRecipeVisible 检查菜谱对给定的用户是否可见，私有的菜谱只有创建者可见
+func (s *SInfo) CheckRecipeVisible(recipe *model.Recipe, userId int64) bool {
+	return recipe.Visibility != VisibilityPrivate || recipe.OwnerId == userId
+}
+
+// GetVisibleRecipe 根据ID获取对给定的用户可见的菜谱，其他用户的私有菜谱视为不存在
+func (s *SInfo) GetVisibleRecipe(ctx context.Context, userId, recipeId int64) (*model.Recipe, error) {
+	// 获取菜谱的信息
+	recipe, err := s.GetRecipe(ctx, recipeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果菜谱对这个用户不可见，返回菜谱不存在的错误
+	if !s.CheckRecipeVisible(recipe, userId) {
+		return nil, fmt.Errorf("recipe not found")
+	}
+
+	return recipe, nil
+}
+
+// GetVisibleRecipes 根据ID批量获取对给定的用户可见的菜谱，返回菜谱ID和菜谱的对应关系，不存在的菜谱会被忽略
+func (s *SInfo) GetVisibleRecipes(ctx context.Context, userId int64, recipeIds []int64) (map[int64]*model.Recipe, error) {
+	res := make(map[int64]*model.Recipe, len(recipeIds))
+	if len(recipeIds) == 0 {
+		return res, nil
+	}
+
+	// 在数据库中查找菜谱
+	cursor, err := g.MongoDB.Database("food").Collection("recipe").
+		Find(ctx, bson.D{
+			{Key: "recipe_id", Value: bson.D{{Key: "$in", Value: recipeIds}}},
+			insSearch.GetVisibilityFilter(userId),
+		})
+	if err != nil {
+		g.Logger.Errorf("query [recipe] documents failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	var recipes []*model.Recipe
+	if err = cursor.All(ctx, &recipes); err != nil {
+		g.Logger.Errorf("decode [recipe] documents failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+	for _, recipe := range recipes {
+		res[recipe.RecipeId] = recipe
+	}
+
+	return res, nil
+}
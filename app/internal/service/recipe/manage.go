@@ -0,0 +1,385 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	g "main/app/global"
+	"main/app/internal/model"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SManage 定义一个管理用户菜谱的结构体
+type SManage struct{}
+
+// 定义菜谱的可见性
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+// MaxImages 定义一个菜谱最多可以有的图片数量
+const MaxImages = 20
+
+// MaxTime 定义菜谱的时间的上限，单位为秒，包括需要发酵或腌制几周的菜谱
+const MaxTime = 60 * 24 * 3600
+
+// MaxNutrition 定义菜谱的每一项营养成分的上限
+const MaxNutrition = 1000000
+
+// DietaryLabels 定义菜谱可以使用的饮食习惯标签
+var DietaryLabels = map[string]bool{
+	"non-halal":      true,
+	"non-vegetarian": true,
+	"non-vegan":      true,
+}
+
+// ValidateRecipe 检查用户提交的菜谱是否有效，并对内容进行规范化
+func (s *SManage) ValidateRecipe(form *model.RecipeForm) error {
+	// 去掉名称、分类和描述两端的空白
+	form.Name = strings.TrimSpace(form.Name)
+	form.Category = strings.TrimSpace(form.Category)
+	form.Description = strings.TrimSpace(form.Description)
+
+	// 检查名称、分类和描述
+	if form.Name == "" {
+		return fmt.Errorf("name cannot be null")
+	}
+	if len([]rune(form.Name)) > 200 {
+		return fmt.Errorf("name is too long")
+	}
+	if len([]rune(form.Category)) > 100 {
+		return fmt.Errorf("category is too long")
+	}
+	if len([]rune(form.Description)) > 5000 {
+		return fmt.Errorf("description is too long")
+	}
+
+	// 检查图片，只允许http和https的链接
+	if len(form.Images) > MaxImages {
+		return fmt.Errorf("too many images")
+	}
+	for _, image := range form.Images {
+		u, err := url.Parse(image)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid image url")
+		}
+	}
+
+	// 检查饮食习惯标签
+	for _, label := range form.Dietary {
+		if !DietaryLabels[label] {
+			return fmt.Errorf("invalid dietary")
+		}
+	}
+
+	// 检查关键词、步骤和食材
+	var err error
+	if form.Keywords, err = cleanList(form.Keywords, 30, 50, "keywords"); err != nil {
+		return err
+	}
+	if form.Instruction, err = cleanList(form.Instruction, 100, 2000, "instruction"); err != nil {
+		return err
+	}
+	if len(form.Instruction) == 0 {
+		return fmt.Errorf("instruction cannot be null")
+	}
+	if form.Ingredients, err = cleanList(form.Ingredients, 100, 200, "ingredients"); err != nil {
+		return err
+	}
+	if len(form.Ingredients) == 0 {
+		return fmt.Errorf("ingredients cannot be null")
+	}
+
+	// 检查份数
+	if form.Servings < 0 || form.Servings > 100 {
+		return fmt.Errorf("invalid servings")
+	}
+
+	// 检查时间，总时间为空时使用烹饪时间和准备时间之和，上限避免相加时溢出
+	for _, v := range []int64{form.CookTime, form.PerpTime, form.TotalTime} {
+		if v < 0 || v > MaxTime {
+			return fmt.Errorf("invalid time")
+		}
+	}
+	if form.TotalTime == 0 {
+		form.TotalTime = form.CookTime + form.PerpTime
+	}
+	if form.TotalTime < form.CookTime+form.PerpTime {
+		return fmt.Errorf("total_time cannot be less than cook_time plus perp_time")
+	}
+
+	// 检查营养成分，NaN和Inf无法编码为JSON，会导致包含这个菜谱的响应都失败
+	for _, v := range []float64{form.Calories, form.Fat, form.SaturatedFat, form.Sodium,
+		form.Carbohydrate, form.Fiber, form.Sugar, form.Protein} {
+		if !ValidNutrition(v) {
+			return fmt.Errorf("invalid nutrition")
+		}
+	}
+	if form.SaturatedFat > form.Fat {
+		return fmt.Errorf("saturated_fat cannot be greater than fat")
+	}
+	if form.Sugar+form.Fiber > form.Carbohydrate && form.Carbohydrate > 0 {
+		return fmt.Errorf("sugar plus fiber cannot be greater than carbohydrate")
+	}
+
+	// 检查可见性，为空时默认为私有
+	switch form.Visibility {
+	case "":
+		form.Visibility = VisibilityPrivate
+	case VisibilityPublic, VisibilityPrivate:
+	default:
+		return fmt.Errorf("invalid visibility")
+	}
+
+	return nil
+}
+
+// cleanList 去掉列表中的空白项，并检查列表的长度和每一项的长度
+func cleanList(list []string, maxLen, maxItemLen int, name string) ([]string, error) {
+	var res []string
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if len([]rune(item)) > maxItemLen {
+			return nil, fmt.Errorf("%s item is too long", name)
+		}
+		res = append(res, item)
+	}
+	if len(res) > maxLen {
+		return nil, fmt.Errorf("too many %s", name)
+	}
+	return res, nil
+}
+
+// AllocateRecipeId 从计数器中分配一个新的菜谱ID
+func (s *SManage) AllocateRecipeId(ctx context.Context) (int64, error) {
+	// 计数器的值加1，并返回更新后的值
+	counter := struct {
+		Seq int64 `bson:"seq"`
+	}{}
+	err := g.MongoDB.Database("food").Collection("counter").
+		FindOneAndUpdate(ctx,
+			bson.D{{Key: "_id", Value: "recipe_id"}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "seq", Value: 1}}}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).
+		Decode(&counter)
+	if err != nil {
+		g.Logger.Errorf("update [counter] document failed, err: %v", err)
+		return 0, fmt.Errorf("internal err")
+	}
+
+	return counter.Seq, nil
+}
+
+// CreateRecipe 创建一个用户的菜谱
+func (s *SManage) CreateRecipe(ctx context.Context, userId int64, form *model.RecipeForm) (*model.Recipe, error) {
+	// 分配菜谱ID
+	recipeId, err := s.AllocateRecipeId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建一个菜谱的对象
+	now := time.Now()
+	recipe := &model.Recipe{
+		RecipeId:   recipeId,
+		OwnerId:    userId,
+		Version:    1,
+		CreateTime: now,
+	}
+	applyForm(recipe, form, now)
+
+	// 在数据库中创建菜谱
+	if err = s.insertRecipe(ctx, recipe, ActionCreate); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+// ForkRecipe 将菜谱复制为用户自己的私有菜谱，并记录复制来源的菜谱
+func (s *SManage) ForkRecipe(ctx context.Context, userId int64, source *model.Recipe) (*model.Recipe, error) {
+	// 分配菜谱ID
+	recipeId, err := s.AllocateRecipeId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 复制菜谱的内容，收藏和评价不复制
+	now := time.Now()
+	recipe := &model.Recipe{
+		RecipeId:   recipeId,
+		OwnerId:    userId,
+		ParentId:   source.RecipeId,
+		Version:    1,
+		CreateTime: now,
+	}
+	applyForm(recipe, recipeForm(source), now)
+	recipe.Visibility = VisibilityPrivate
+
+	// 在数据库中创建菜谱
+	if err = s.insertRecipe(ctx, recipe, ActionFork); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+// insertRecipe 在数据库中创建菜谱，并保存第一个版本，保存版本失败时只记录日志，第一次修改前会补充
+func (s *SManage) insertRecipe(ctx context.Context, recipe *model.Recipe, action string) error {
+	_, err := g.MongoDB.Database("food").Collection("recipe").
+		InsertOne(ctx, recipe)
+	if err != nil {
+		g.Logger.Errorf("insert [recipe] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	_ = insVersion.RecordVersion(ctx, recipe, action)
+
+	return nil
+}
+
+// GetOwnRecipe 获取用户自己的菜谱，菜谱不存在或者不属于这个用户时返回错误
+func (s *SManage) GetOwnRecipe(ctx context.Context, userId, recipeId int64) (*model.Recipe, error) {
+	// 获取菜谱的信息，其他用户的私有菜谱视为不存在
+	recipe, err := insInfo.GetVisibleRecipe(ctx, userId, recipeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只有创建菜谱的用户才能修改菜谱
+	if recipe.OwnerId != userId {
+		return nil, fmt.Errorf("permission denied")
+	}
+
+	return recipe, nil
+}
+
+// UpdateRecipe 更新用户自己的菜谱，并保存新的版本
+func (s *SManage) UpdateRecipe(ctx context.Context, recipe *model.Recipe, form *model.RecipeForm) error {
+	// 修改前保证当前版本有快照
+	if err := insVersion.EnsureVersion(ctx, recipe); err != nil {
+		return err
+	}
+
+	// 使用提交的内容更新菜谱的对象
+	applyForm(recipe, form, time.Now())
+
+	return s.saveRecipe(ctx, recipe, ActionUpdate)
+}
+
+// RevertRecipe 将用户自己的菜谱恢复到指定版本的内容，恢复后产生一个新的版本
+func (s *SManage) RevertRecipe(ctx context.Context, recipe *model.Recipe, version *model.RecipeVersion) error {
+	// 修改前保证当前版本有快照
+	if err := insVersion.EnsureVersion(ctx, recipe); err != nil {
+		return err
+	}
+
+	// 使用版本的快照更新菜谱的对象
+	applyForm(recipe, recipeForm(version.Recipe), time.Now())
+
+	return s.saveRecipe(ctx, recipe, ActionRevert)
+}
+
+// saveRecipe 在数据库中更新菜谱的内容并将版本号加1，再保存新版本的快照
+// 调用前需要先使用EnsureVersion保存修改前的版本
+func (s *SManage) saveRecipe(ctx context.Context, recipe *model.Recipe, action string) error {
+	// 在数据库中更新菜谱，并获取更新后的菜谱
+	err := g.MongoDB.Database("food").Collection("recipe").
+		FindOneAndUpdate(ctx,
+			bson.D{{Key: "recipe_id", Value: recipe.RecipeId}},
+			bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "images", Value: recipe.Images},
+					{Key: "name", Value: recipe.Name},
+					{Key: "category", Value: recipe.Category},
+					{Key: "dietary", Value: recipe.Dietary},
+					{Key: "description", Value: recipe.Description},
+					{Key: "keywords", Value: recipe.Keywords},
+					{Key: "instruction", Value: recipe.Instruction},
+					{Key: "ingredients", Value: recipe.Ingredients},
+					{Key: "servings", Value: recipe.Servings},
+					{Key: "cook_time", Value: recipe.CookTime},
+					{Key: "perp_time", Value: recipe.PerpTime},
+					{Key: "total_time", Value: recipe.TotalTime},
+					{Key: "calories", Value: recipe.Calories},
+					{Key: "fat", Value: recipe.Fat},
+					{Key: "saturated_fat", Value: recipe.SaturatedFat},
+					{Key: "sodium", Value: recipe.Sodium},
+					{Key: "carbohydrate", Value: recipe.Carbohydrate},
+					{Key: "fiber", Value: recipe.Fiber},
+					{Key: "sugar", Value: recipe.Sugar},
+					{Key: "protein", Value: recipe.Protein},
+					{Key: "visibility", Value: recipe.Visibility},
+					{Key: "update_time", Value: recipe.UpdateTime},
+				}},
+				{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(recipe)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("recipe not found")
+		}
+		g.Logger.Errorf("update [recipe] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	// 保存版本失败时只记录日志，下次修改前会补充这个版本的快照
+	_ = insVersion.RecordVersion(ctx, recipe, action)
+
+	return nil
+}
+
+// DeleteRecipe 删除用户自己的菜谱
+func (s *SManage) DeleteRecipe(ctx context.Context, recipeId int64) error {
+	// 在数据库中删除菜谱
+	_, err := g.MongoDB.Database("food").Collection("recipe").
+		DeleteOne(ctx, bson.D{{Key: "recipe_id", Value: recipeId}})
+	if err != nil {
+		g.Logger.Errorf("delete [recipe] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// ValidNutrition 检查营养成分的数值是否有效，不能是负数、NaN、Inf或者超过上限
+func ValidNutrition(v float64) bool {
+	return !math.IsNaN(v) && v >= 0 && v <= MaxNutrition
+}
+
+// applyForm 将用户提交的内容复制到菜谱的对象中
+func applyForm(recipe *model.Recipe, form *model.RecipeForm, now time.Time) {
+	recipe.Images = form.Images
+	recipe.Name = form.Name
+	recipe.Category = form.Category
+	recipe.Dietary = form.Dietary
+	recipe.Description = form.Description
+	recipe.Keywords = form.Keywords
+	recipe.Instruction = form.Instruction
+	recipe.Ingredients = form.Ingredients
+	recipe.Servings = form.Servings
+	recipe.CookTime = form.CookTime
+	recipe.PerpTime = form.PerpTime
+	recipe.TotalTime = form.TotalTime
+	recipe.Calories = form.Calories
+	recipe.Fat = form.Fat
+	recipe.SaturatedFat = form.SaturatedFat
+	recipe.Sodium = form.Sodium
+	recipe.Carbohydrate = form.Carbohydrate
+	recipe.Fiber = form.Fiber
+	recipe.Sugar = form.Sugar
+	recipe.Protein = form.Protein
+	recipe.Visibility = form.Visibility
+	recipe.UpdateTime = now
+}
@@ -0,0 +1,47 @@
+package recipe
+
+import (
+	"main/app/internal/model"
+	"math"
+	"testing"
+)
+
+func TestValidateRecipe(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(form *model.RecipeForm)
+		want string
+	}{
+		{"valid", func(form *model.RecipeForm) {}, ""},
+		{"NaN calories", func(form *model.RecipeForm) { form.Calories = math.NaN() }, "invalid nutrition"},
+		{"Inf protein", func(form *model.RecipeForm) { form.Protein = math.Inf(1) }, "invalid nutrition"},
+		{"too much sodium", func(form *model.RecipeForm) { form.Sodium = MaxNutrition + 1 }, "invalid nutrition"},
+		{"negative fat", func(form *model.RecipeForm) { form.Fat = -1 }, "invalid nutrition"},
+		{"too long cook time", func(form *model.RecipeForm) { form.CookTime = MaxTime + 1 }, "invalid time"},
+		{"overflowing times", func(form *model.RecipeForm) {
+			form.CookTime, form.PerpTime = math.MaxInt64, math.MaxInt64
+		}, "invalid time"},
+	}
+
+	s := &SManage{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := &model.RecipeForm{
+				Name:        "Pancakes",
+				Instruction: []string{"Mix and fry."},
+				Ingredients: []string{"1 cup flour", "1 egg"},
+				CookTime:    600,
+				Calories:    350,
+			}
+			tt.edit(form)
+
+			got := ""
+			if err := s.ValidateRecipe(form); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("ValidateRecipe() err = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
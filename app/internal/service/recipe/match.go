@@ -0,0 +1,85 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/utils/ingredient"
+)
+
+// MatchRecipes 根据用户已有的食材查找菜谱，并按食材的覆盖率排序
+// 菜谱中的每一种食材只要匹配任意一个已有的食材就算作已有，覆盖率为已有的食材数量除以菜谱的食材数量
+// 已有的食材按规范化后的名称整个单词匹配，例如salt不匹配unsalted butter
+func (s *SSearch) MatchRecipes(ctx context.Context, pantry []string, filter bson.D, minCoverage float64, limit int64) ([]*model.RecipeMatch, error) {
+	// 将已有的食材转换为正则表达式，按整个单词忽略大小写匹配
+	regexes := bson.A{}
+	conds := bson.A{}
+	for _, name := range pantry {
+		pattern := ingredient.NamePattern(name)
+		if pattern == "" {
+			continue
+		}
+		regexes = append(regexes, primitive.Regex{Pattern: pattern, Options: "i"})
+		conds = append(conds, bson.D{{Key: "$regexMatch", Value: bson.D{
+			{Key: "input", Value: "$$ingredient"},
+			{Key: "regex", Value: pattern},
+			{Key: "options", Value: "i"},
+		}}})
+	}
+
+	// 只查找至少包含一种已有食材的菜谱
+	match := append(bson.D{{Key: "ingredients", Value: bson.D{{Key: "$in", Value: regexes}}}}, filter...)
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		// 找出菜谱中已有的食材
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "matched", Value: bson.D{{Key: "$filter", Value: bson.D{
+				{Key: "input", Value: "$ingredients"},
+				{Key: "as", Value: "ingredient"},
+				{Key: "cond", Value: bson.D{{Key: "$or", Value: conds}}},
+			}}}},
+		}}},
+		// 计算覆盖率和缺少的食材
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "recipe", Value: "$$ROOT"},
+			{Key: "matched", Value: 1},
+			{Key: "missing", Value: bson.D{{Key: "$setDifference", Value: bson.A{"$ingredients", "$matched"}}}},
+			{Key: "coverage", Value: bson.D{{Key: "$divide", Value: bson.A{
+				bson.D{{Key: "$size", Value: "$matched"}},
+				bson.D{{Key: "$max", Value: bson.A{bson.D{{Key: "$size", Value: "$ingredients"}}, 1}}},
+			}}}},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "coverage", Value: bson.D{{Key: "$gte", Value: minCoverage}}}}}},
+		// 覆盖率高的排在前面，覆盖率相同时缺少的食材少的排在前面
+		bson.D{{Key: "$addFields", Value: bson.D{{Key: "missing_count", Value: bson.D{{Key: "$size", Value: "$missing"}}}}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "coverage", Value: -1},
+			{Key: "missing_count", Value: 1},
+			{Key: "recipe._id", Value: 1},
+		}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+
+	// 执行聚合
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		g.Logger.Errorf("aggregate [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将聚合的结果解码为匹配结果的列表
+	var results []*model.RecipeMatch
+	err = cur.All(ctx, &results)
+	if err != nil {
+		g.Logger.Errorf("decode [recipe] match failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return results, nil
+}
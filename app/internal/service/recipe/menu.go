@@ -0,0 +1,270 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	g "main/app/global"
+	"main/app/internal/model"
+	"math"
+	"slices"
+)
+
+// SMenu 定义一个生成菜单的结构体
+type SMenu struct{}
+
+// MenuMeals 定义一日菜单的餐次和每一餐占一天热量的比例，按一天中的顺序排列
+var MenuMeals = []struct {
+	Name    string
+	Share   float64
+	Pattern string // 优先选择分类或者关键词匹配这个正则表达式的菜谱
+}{
+	{"breakfast", 0.25, "breakfast|brunch|egg|oat|pancake|muffin|smoothie"},
+	{"lunch", 0.35, "lunch|salad|sandwich|soup|wrap|bowl"},
+	{"dinner", 0.3, "dinner|main|meat|chicken|beef|pork|fish|pasta|stew|curry"},
+	{"snack", 0.1, "snack|appetizer|dessert|bar|cookie|fruit|dip|beverage"},
+}
+
+const (
+	// menuCandidates 每一餐随机选取的候选菜谱数量
+	menuCandidates = 25
+	// menuMinMatched 匹配餐次的候选菜谱少于这个数量时，补充不限分类的候选菜谱
+	menuMinMatched = 5
+)
+
+// CheckMeal 检查餐次是否有效
+func (s *SMenu) CheckMeal(meal string) bool {
+	for _, m := range MenuMeals {
+		if m.Name == meal {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTarget 根据热量和蛋白质、脂肪、碳水化合物的热量比例计算营养成分的目标，蛋白质和碳水化合物每克4千卡，脂肪每克9千卡
+func (s *SMenu) GetTarget(calories, proteinRatio, fatRatio, carbohydrateRatio float64) *model.Nutrition {
+	return &model.Nutrition{
+		Calories:     calories,
+		Protein:      math.Round(calories*proteinRatio/4*10) / 10,
+		Fat:          math.Round(calories*fatRatio/9*10) / 10,
+		Carbohydrate: math.Round(calories*carbohydrateRatio/4*10) / 10,
+	}
+}
+
+// PlanDay 为给定的餐次从符合过滤条件的菜谱中选择最接近目标的组合，每一餐一份
+// 每一餐先按热量的比例随机选取候选菜谱，然后枚举所有的组合，选择热量和三大营养素的相对误差最小的组合
+func (s *SMenu) PlanDay(ctx context.Context, filter bson.D, meals []string, target *model.Nutrition) (*model.DayMenu, error) {
+	// 计算选择的餐次占一天热量的比例之和，用于重新分配热量
+	var total float64
+	for _, meal := range MenuMeals {
+		if slices.Contains(meals, meal.Name) {
+			total += meal.Share
+		}
+	}
+
+	// 获取每一餐的候选菜谱
+	var names []string
+	var candidates [][]*model.Recipe
+	for _, meal := range MenuMeals {
+		if !slices.Contains(meals, meal.Name) {
+			continue
+		}
+		calories := target.Calories * meal.Share / total
+		recipes, err := s.getCandidates(ctx, filter, meal.Pattern, calories)
+		if err != nil {
+			return nil, err
+		}
+		if len(recipes) == 0 {
+			return nil, fmt.Errorf("no recipes match the targets for %s", meal.Name)
+		}
+		names = append(names, meal.Name)
+		candidates = append(candidates, recipes)
+	}
+
+	// 计算每一个候选菜谱一份的营养成分
+	nutrition := make([][]*model.Nutrition, len(candidates))
+	for i, recipes := range candidates {
+		for _, recipe := range recipes {
+			nutrition[i] = append(nutrition[i], perServing(recipe))
+		}
+	}
+
+	// 枚举所有的组合，同一个菜谱不会出现两次
+	best, bestScore := make([]int, len(candidates)), math.Inf(1)
+	current := make([]int, len(candidates))
+	sum := &model.Nutrition{}
+	var search func(i int)
+	search = func(i int) {
+		if i == len(candidates) {
+			if score := menuScore(sum, target); score < bestScore {
+				bestScore = score
+				copy(best, current)
+			}
+			return
+		}
+	next:
+		for j, recipe := range candidates[i] {
+			for k := 0; k < i; k++ {
+				if candidates[k][current[k]].RecipeId == recipe.RecipeId {
+					continue next
+				}
+			}
+			current[i] = j
+			addTo(sum, nutrition[i][j], 1)
+			search(i + 1)
+			addTo(sum, nutrition[i][j], -1)
+		}
+	}
+	search(0)
+	if math.IsInf(bestScore, 1) {
+		return nil, fmt.Errorf("no recipes match the targets")
+	}
+
+	// 生成菜单
+	menu := &model.DayMenu{Total: &model.Nutrition{}, Target: target}
+	for i, j := range best {
+		menu.Meals = append(menu.Meals, &model.MenuItem{
+			Meal:      names[i],
+			Recipe:    candidates[i][j],
+			Nutrition: roundedNutrition(nutrition[i][j]),
+		})
+		addTo(menu.Total, nutrition[i][j], 1)
+	}
+	menu.Total = roundedNutrition(menu.Total)
+
+	return menu, nil
+}
+
+// getCandidates 随机选取一份的热量在目标的50%到150%之间的候选菜谱，优先选择匹配餐次的菜谱
+func (s *SMenu) getCandidates(ctx context.Context, filter bson.D, pattern string, calories float64) ([]*model.Recipe, error) {
+	// 匹配餐次的菜谱
+	regex := primitive.Regex{Pattern: pattern, Options: "i"}
+	matched, err := s.sampleRecipes(ctx, filter, bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "category", Value: regex}},
+		bson.D{{Key: "keywords", Value: regex}},
+	}}}, calories, menuCandidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(matched) >= menuMinMatched {
+		return matched, nil
+	}
+
+	// 匹配餐次的菜谱太少时，补充不限分类的菜谱
+	others, err := s.sampleRecipes(ctx, filter, bson.D{}, calories, menuCandidates-len(matched))
+	if err != nil {
+		return nil, err
+	}
+	for _, recipe := range others {
+		if !slices.ContainsFunc(matched, func(m *model.Recipe) bool { return m.RecipeId == recipe.RecipeId }) {
+			matched = append(matched, recipe)
+		}
+	}
+
+	return matched, nil
+}
+
+// sampleRecipes 在符合过滤条件的菜谱中，随机选取一份的热量在目标的50%到150%之间的菜谱
+func (s *SMenu) sampleRecipes(ctx context.Context, filter, extra bson.D, calories float64, size int) ([]*model.Recipe, error) {
+	match := append(bson.D{{Key: "calories", Value: bson.D{{Key: "$gt", Value: 0}}}}, filter...)
+	match = append(match, extra...)
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		// 计算一份的热量，没有记录份数的菜谱视为1份
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "serving_calories", Value: bson.D{{Key: "$divide", Value: bson.A{
+				"$calories",
+				bson.D{{Key: "$max", Value: bson.A{"$servings", 1}}},
+			}}}},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "serving_calories", Value: bson.D{
+			{Key: "$gte", Value: calories * 0.5},
+			{Key: "$lte", Value: calories * 1.5},
+		}}}}},
+		bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: size}}}},
+	}
+
+	// 执行聚合
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		g.Logger.Errorf("aggregate [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将聚合的结果解码为菜谱的列表
+	var recipes []*model.Recipe
+	if err = cur.All(ctx, &recipes); err != nil {
+		g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return recipes, nil
+}
+
+// menuScore 计算营养成分和目标的误差，热量的相对误差和三大营养素的平均相对误差之和
+func menuScore(sum, target *model.Nutrition) float64 {
+	relative := func(v, t float64) float64 {
+		if t <= 0 {
+			return 0
+		}
+		return math.Abs(v-t) / t
+	}
+
+	return relative(sum.Calories, target.Calories) +
+		(relative(sum.Protein, target.Protein)+
+			relative(sum.Fat, target.Fat)+
+			relative(sum.Carbohydrate, target.Carbohydrate))/3
+}
+
+// perServing 计算菜谱一份的营养成分，没有记录份数的菜谱视为1份
+func perServing(recipe *model.Recipe) *model.Nutrition {
+	base := float64(recipe.Servings)
+	if base <= 0 {
+		base = 1
+	}
+
+	return &model.Nutrition{
+		Calories:     recipe.Calories / base,
+		Fat:          recipe.Fat / base,
+		SaturatedFat: recipe.SaturatedFat / base,
+		Sodium:       recipe.Sodium / base,
+		Carbohydrate: recipe.Carbohydrate / base,
+		Fiber:        recipe.Fiber / base,
+		Sugar:        recipe.Sugar / base,
+		Protein:      recipe.Protein / base,
+	}
+}
+
+// addTo 将营养成分乘以sign后加到合计中
+func addTo(sum, n *model.Nutrition, sign float64) {
+	sum.Calories += n.Calories * sign
+	sum.Fat += n.Fat * sign
+	sum.SaturatedFat += n.SaturatedFat * sign
+	sum.Sodium += n.Sodium * sign
+	sum.Carbohydrate += n.Carbohydrate * sign
+	sum.Fiber += n.Fiber * sign
+	sum.Sugar += n.Sugar * sign
+	sum.Protein += n.Protein * sign
+}
+
+// roundedNutrition 返回保留一位小数的营养成分
+func roundedNutrition(n *model.Nutrition) *model.Nutrition {
+	round := func(v float64) float64 {
+		return math.Round(v*10) / 10
+	}
+
+	return &model.Nutrition{
+		Calories:     round(n.Calories),
+		Fat:          round(n.Fat),
+		SaturatedFat: round(n.SaturatedFat),
+		Sodium:       round(n.Sodium),
+		Carbohydrate: round(n.Carbohydrate),
+		Fiber:        round(n.Fiber),
+		Sugar:        round(n.Sugar),
+		Protein:      round(n.Protein),
+	}
+}
@@ -0,0 +1,225 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	g "main/app/global"
+	"main/app/internal/model"
+	"math"
+	"sort"
+	"strings"
+)
+
+// recommendWeights 定义推荐的得分中关键词、分类和饮食习惯的权重，权重之和为1
+var recommendWeights = struct {
+	keywords, category, dietary float64
+}{0.5, 0.3, 0.2}
+
+const (
+	// RecommendCandidates 计算推荐的得分时最多比较的候选菜谱数量，也是推荐结果分页的上限
+	RecommendCandidates = 1000
+	// recommendTopKeywords 口味画像中使用的关键词数量
+	recommendTopKeywords = 20
+	// recommendTopCategories 口味画像中使用的分类数量
+	recommendTopCategories = 5
+)
+
+// tasteProfile 定义根据用户收藏的菜谱得到的口味画像
+type tasteProfile struct {
+	keywords   map[string]float64 // 关键词和出现的次数
+	categories map[string]float64 // 分类和出现的次数
+	dietary    map[string]float64 // 饮食习惯标签和带有这个标签的菜谱的比例
+	topKeyword float64            // 出现次数最多的几个关键词的次数之和
+	topCount   float64            // 出现次数最多的分类的次数
+}
+
+// RecommendRecipes 根据用户收藏的菜谱推荐其他的菜谱，按推荐的得分降序排列
+// 候选菜谱是符合过滤条件、没有被收藏并且分类或者关键词和口味画像相同的菜谱
+func (s *SSearch) RecommendRecipes(ctx context.Context, collected []*model.Recipe, filter bson.D, limit, page int) ([]*model.RecipeScore, error) {
+	profile := newTasteProfile(collected)
+
+	// 口味画像中的关键词和分类
+	var or bson.A
+	if keywords := topKeys(profile.keywords, recommendTopKeywords); len(keywords) > 0 {
+		or = append(or, bson.D{{Key: "keywords", Value: bson.D{{Key: "$in", Value: keywords}}}})
+	}
+	if categories := topKeys(profile.categories, recommendTopCategories); len(categories) > 0 {
+		or = append(or, bson.D{{Key: "category", Value: bson.D{{Key: "$in", Value: categories}}}})
+	}
+	if len(or) == 0 {
+		return []*model.RecipeScore{}, nil
+	}
+
+	// 排除已经收藏的菜谱
+	collectedIds := make([]int64, 0, len(collected))
+	for _, recipe := range collected {
+		collectedIds = append(collectedIds, recipe.RecipeId)
+	}
+	match := append(bson.D{
+		{Key: "recipe_id", Value: bson.D{{Key: "$nin", Value: collectedIds}}},
+		{Key: "$or", Value: or},
+	}, filter...)
+
+	// 在数据库中查找候选菜谱，收藏次数多的优先
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Find(ctx, match, options.Find().
+			SetSort(bson.D{{Key: "collect_count", Value: -1}, {Key: "_id", Value: 1}}).
+			SetLimit(RecommendCandidates))
+	if err != nil {
+		g.Logger.Errorf("query [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将查找的结果解码为菜谱的列表
+	var candidates []*model.Recipe
+	if err = cur.All(ctx, &candidates); err != nil {
+		g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 计算每一个候选菜谱的得分
+	res := make([]*model.RecipeScore, 0, len(candidates))
+	for _, candidate := range candidates {
+		res = append(res, &model.RecipeScore{
+			Recipe: candidate,
+			Score:  math.Round(profile.score(candidate)*1000) / 1000,
+		})
+	}
+
+	// 按得分降序排列，得分相同时收藏次数多的在前面
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Recipe.CollectCount > res[j].Recipe.CollectCount
+	})
+
+	// 分页，页数过大时乘法会溢出为负数
+	start := limit * (page - 1)
+	if limit <= 0 || page <= 0 || start < 0 || start >= len(res) {
+		return []*model.RecipeScore{}, nil
+	}
+	return res[start:min(start+limit, len(res))], nil
+}
+
+// PopularRecipes 获取收藏次数最多的菜谱，用于没有收藏的用户
+func (s *SSearch) PopularRecipes(ctx context.Context, filter bson.D, limit, page int) ([]*model.RecipeScore, error) {
+	// 页数过大时乘法会溢出为负数
+	skip := limit * (page - 1)
+	if limit <= 0 || page <= 0 || skip < 0 {
+		return []*model.RecipeScore{}, nil
+	}
+
+	// 在数据库中分页查找收藏次数最多的菜谱
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "collect_count", Value: -1}, {Key: "_id", Value: 1}}).
+			SetSkip(int64(skip)).
+			SetLimit(int64(limit)))
+	if err != nil {
+		g.Logger.Errorf("query [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将查找的结果解码为菜谱的列表
+	var recipes []*model.Recipe
+	if err = cur.All(ctx, &recipes); err != nil {
+		g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 热门的菜谱没有推荐的得分
+	res := make([]*model.RecipeScore, 0, len(recipes))
+	for _, recipe := range recipes {
+		res = append(res, &model.RecipeScore{Recipe: recipe})
+	}
+
+	return res, nil
+}
+
+// newTasteProfile 根据用户收藏的菜谱得到口味画像
+func newTasteProfile(collected []*model.Recipe) *tasteProfile {
+	p := &tasteProfile{
+		keywords:   make(map[string]float64),
+		categories: make(map[string]float64),
+		dietary:    make(map[string]float64),
+	}
+
+	for _, recipe := range collected {
+		for _, keyword := range recipe.Keywords {
+			p.keywords[keyword]++
+		}
+		if recipe.Category != "" {
+			p.categories[recipe.Category]++
+		}
+		for _, label := range recipe.Dietary {
+			p.dietary[label]++
+		}
+	}
+
+	// 饮食习惯标签转换为比例
+	for label := range p.dietary {
+		p.dietary[label] /= float64(len(collected))
+	}
+	// 用于归一化的关键词次数之和和分类的最大次数
+	for _, keyword := range topKeys(p.keywords, 5) {
+		p.topKeyword += p.keywords[keyword]
+	}
+	for _, cnt := range p.categories {
+		p.topCount = math.Max(p.topCount, cnt)
+	}
+
+	return p
+}
+
+// score 计算菜谱和口味画像的匹配程度
+func (p *tasteProfile) score(recipe *model.Recipe) float64 {
+	var res float64
+
+	// 关键词的得分为匹配的关键词的次数之和，除以出现次数最多的几个关键词的次数之和，最大为1
+	if p.topKeyword > 0 {
+		var sum float64
+		for _, keyword := range recipe.Keywords {
+			sum += p.keywords[keyword]
+		}
+		res += recommendWeights.keywords * math.Min(sum/p.topKeyword, 1)
+	}
+
+	// 分类的得分为分类的次数除以最大次数
+	if p.topCount > 0 {
+		res += recommendWeights.category * p.categories[recipe.Category] / p.topCount
+	}
+
+	// 饮食习惯的得分为菜谱的每一个标签在收藏中出现的比例之积，例如从不收藏non-vegan的用户不会被推荐non-vegan的菜谱
+	dietary := 1.0
+	for _, label := range recipe.Dietary {
+		if DietaryLabels[label] {
+			dietary *= p.dietary[label]
+		}
+	}
+	res += recommendWeights.dietary * dietary
+
+	return res
+}
+
+// topKeys 获取次数最多的n个键，次数相同时按键排序
+func topKeys(counts map[string]float64, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		if strings.TrimSpace(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
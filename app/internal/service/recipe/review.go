@@ -0,0 +1,142 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+	g "main/app/global"
+	"main/app/internal/dao"
+	"main/app/internal/model"
+	"math"
+	"strings"
+)
+
+// SReview 定义一个菜谱评价的结构体
+type SReview struct{}
+
+// ValidateReview 检查评价的星级和内容，并去掉内容两端的空白
+func (s *SReview) ValidateReview(review *model.RecipeReview) error {
+	review.Content = strings.TrimSpace(review.Content)
+
+	if review.Rating < 1 || review.Rating > 5 {
+		return fmt.Errorf("invalid rating")
+	}
+	if len([]rune(review.Content)) > 2000 {
+		return fmt.Errorf("content is too long")
+	}
+
+	return nil
+}
+
+// GetReview 获取用户对菜谱的评价
+func (s *SReview) GetReview(ctx context.Context, recipeId, userId int64) (*model.RecipeReview, error) {
+	review, err := dao.Recipe().Review().GetReview(ctx, recipeId, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("review not found")
+		}
+		g.Logger.Errorf("query [recipe_review] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return review, nil
+}
+
+// CreateReview 创建用户对菜谱的评价，并更新菜谱的评价统计
+func (s *SReview) CreateReview(ctx context.Context, review *model.RecipeReview) error {
+	// 检查用户是否已经评价过这个菜谱
+	_, err := s.GetReview(ctx, review.RecipeId, review.UserId)
+	if err == nil {
+		return fmt.Errorf("review already exist")
+	}
+	if err.Error() != "review not found" {
+		return err
+	}
+
+	// 在数据库中创建评价
+	if err = dao.Recipe().Review().CreateReview(ctx, review); err != nil {
+		g.Logger.Errorf("create [recipe_review] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return s.RefreshRating(ctx, review.RecipeId)
+}
+
+// UpdateReview 更新用户对菜谱的评价，并更新菜谱的评价统计
+func (s *SReview) UpdateReview(ctx context.Context, review *model.RecipeReview) error {
+	if err := dao.Recipe().Review().UpdateReview(ctx, review); err != nil {
+		g.Logger.Errorf("update [recipe_review] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return s.RefreshRating(ctx, review.RecipeId)
+}
+
+// DeleteReview 删除用户对菜谱的评价，并更新菜谱的评价统计
+func (s *SReview) DeleteReview(ctx context.Context, review *model.RecipeReview) error {
+	if err := dao.Recipe().Review().DeleteReview(ctx, review.Id); err != nil {
+		g.Logger.Errorf("delete [recipe_review] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return s.RefreshRating(ctx, review.RecipeId)
+}
+
+// DeleteReviewsByRecipe 删除菜谱的所有评价，用于删除菜谱
+func (s *SReview) DeleteReviewsByRecipe(ctx context.Context, recipeId int64) error {
+	if err := dao.Recipe().Review().DeleteReviewsByRecipe(ctx, recipeId); err != nil {
+		g.Logger.Errorf("delete [recipe_review] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// GetReviewCount 获取菜谱的评价数量
+func (s *SReview) GetReviewCount(ctx context.Context, recipeId int64) (int64, error) {
+	cnt, err := dao.Recipe().Review().GetReviewCount(ctx, recipeId)
+	if err != nil {
+		g.Logger.Errorf("query [recipe_review] record failed, err: %v", err)
+		return -1, fmt.Errorf("internal err")
+	}
+
+	return cnt, nil
+}
+
+// GetReviewsWithLimit 分页获取菜谱的评价，最新的在前面
+func (s *SReview) GetReviewsWithLimit(ctx context.Context, recipeId int64, limit, page int) ([]*model.RecipeReview, error) {
+	reviews, err := dao.Recipe().Review().GetReviewsWithLimit(ctx, recipeId, limit, page)
+	if err != nil {
+		g.Logger.Errorf("query [recipe_review] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return reviews, nil
+}
+
+// RefreshRating 根据MySQL中的评价重新计算菜谱的平均星级和评价数量，并保存到菜谱中，用于搜索和排序
+func (s *SReview) RefreshRating(ctx context.Context, recipeId int64) error {
+	// 计算平均星级和评价数量
+	avg, cnt, err := dao.Recipe().Review().GetRatingStats(ctx, recipeId)
+	if err != nil {
+		g.Logger.Errorf("query [recipe_review] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	// 在数据库中更新菜谱的评价统计，平均星级保留两位小数
+	_, err = g.MongoDB.Database("food").Collection("recipe").
+		UpdateOne(ctx,
+			bson.D{{Key: "recipe_id", Value: recipeId}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "rating_avg", Value: math.Round(avg*100) / 100},
+				{Key: "rating_count", Value: cnt},
+			}}})
+	if err != nil {
+		g.Logger.Errorf("update [recipe] rating failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package recipe
+
+import (
+	"main/app/internal/model"
+	"main/utils/ingredient"
+	"math"
+)
+
+// ScaleRecipe 将菜谱缩放到指定的份数，会修改菜谱的食材、营养成分和份数，返回缩放后解析的食材
+// 菜谱的食材和营养成分对应菜谱的份数，没有记录份数的菜谱视为1份，份数小于等于0时不缩放
+func (s *SInfo) ScaleRecipe(recipe *model.Recipe, servings int64) []*ingredient.Ingredient {
+	parsed := ingredient.ParseAll(recipe.Ingredients)
+	if servings <= 0 {
+		return parsed
+	}
+
+	base := recipe.Servings
+	if base <= 0 {
+		base = 1
+	}
+	factor := float64(servings) / float64(base)
+	recipe.Servings = servings
+	if factor == 1 {
+		return parsed
+	}
+
+	// 缩放食材的数量，没有数量的食材保持不变
+	ingredients := make([]string, 0, len(parsed))
+	for i, item := range parsed {
+		parsed[i] = item.Scale(factor)
+		ingredients = append(ingredients, parsed[i].String())
+	}
+	recipe.Ingredients = ingredients
+
+	// 缩放营养成分，保留一位小数
+	for _, field := range NutritionFields {
+		v := nutritionField(recipe, field)
+		*v = math.Round(*v*factor*10) / 10
+	}
+
+	return parsed
+}
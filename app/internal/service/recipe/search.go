@@ -0,0 +1,264 @@
+package recipe
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cast"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	g "main/app/global"
+	"main/app/internal/model"
+)
+
+// SSearch 定义一个菜谱搜索的结构体
+type SSearch struct{}
+
+// SortFields 定义可以排序的字段，键为请求中的排序参数，值为数据库中的字段
+var SortFields = map[string]string{
+	"total_time": "total_time",
+	"calories":   "calories",
+	"protein":    "protein",
+	"name":       "name",
+	"relevance":  "score",
+	"popularity": "collect_count",
+	"rating":     "rating_avg",
+}
+
+// GetDietaryFilter 获取饮食习惯的过滤条件
+func (s *SSearch) GetDietaryFilter(dietary string) (bson.E, error) {
+	switch dietary {
+	case "halal":
+		// 排除非halal的菜谱
+		return bson.E{
+			Key:   "dietary",
+			Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$in", Value: []string{"non-halal"}}}}},
+		}, nil
+	case "vegetarian":
+		// 排除非vegetarian的菜谱
+		return bson.E{
+			Key:   "dietary",
+			Value: bson.D{{Key: "$nin", Value: []string{"non-vegetarian"}}},
+		}, nil
+	case "vegan":
+		// 排除非vegan的菜谱
+		return bson.E{
+			Key:   "dietary",
+			Value: bson.D{{Key: "$nin", Value: []string{"non-vegan"}}},
+		}, nil
+	}
+
+	// 其他的饮食习惯都是无效的
+	return bson.E{}, fmt.Errorf("invalid dietary")
+}
+
+// GetSort 获取排序条件，order为asc或desc，为空时相关度、热度和评分降序，其他字段升序
+func (s *SSearch) GetSort(sortKey, order, q string) (bson.D, error) {
+	// 如果没有指定排序字段，有搜索词时按相关度排序，否则按_id排序
+	if sortKey == "" {
+		if q == "" {
+			return bson.D{{Key: "_id", Value: 1}}, nil
+		}
+		sortKey = "relevance"
+	}
+
+	// 获取排序字段
+	field, ok := SortFields[sortKey]
+	if !ok {
+		return nil, fmt.Errorf(`invalid param "sort"`)
+	}
+
+	// 相关度得分只有在全文搜索时才存在，并且只能降序
+	if sortKey == "relevance" {
+		if q == "" {
+			return nil, fmt.Errorf(`sort by relevance requires param "q"`)
+		}
+		return bson.D{
+			{Key: field, Value: bson.D{{Key: "$meta", Value: "textScore"}}},
+			{Key: "_id", Value: 1},
+		}, nil
+	}
+
+	// 获取排序方向
+	direction := 1
+	switch order {
+	case "":
+		if sortKey == "popularity" || sortKey == "rating" {
+			direction = -1
+		}
+	case "asc":
+	case "desc":
+		direction = -1
+	default:
+		return nil, fmt.Errorf(`invalid param "order"`)
+	}
+
+	// 使用_id作为第二排序字段，保证分页结果稳定
+	return bson.D{
+		{Key: field, Value: direction},
+		{Key: "_id", Value: direction},
+	}, nil
+}
+
+// CountRecipes 计算匹配过滤器的菜谱数量
+func (s *SSearch) CountRecipes(ctx context.Context, filter bson.D) (int64, error) {
+	// 在集合中计算匹配的文档数量
+	cnt, err := g.MongoDB.Database("food").Collection("recipe").
+		CountDocuments(ctx, filter)
+	if err != nil {
+		g.Logger.Errorf("count [recipe] document failed, err: %v", err)
+		return -1, fmt.Errorf("internal err")
+	}
+
+	return cnt, nil
+}
+
+// FindRecipes 查找匹配过滤器的菜谱
+func (s *SSearch) FindRecipes(ctx context.Context, filter interface{}, option *options.FindOptions) ([]*model.Recipe, error) {
+	// 在集合中查找匹配的文档
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Find(ctx, filter, option)
+	if err != nil {
+		g.Logger.Errorf("find [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+	// 在函数返回后关闭游标
+	defer func(cur *mongo.Cursor, ctx context.Context) {
+		err := cur.Close(ctx)
+		if err != nil {
+			g.Logger.Errorf("close [recipe] document failed, err: %v", err)
+		}
+	}(cur, ctx)
+
+	// 将所有文档解码为菜谱的列表
+	var results []*model.Recipe
+	err = cur.All(ctx, &results)
+	if err != nil {
+		g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return results, nil
+}
+
+// IncrCollectCount 增加菜谱的收藏次数，delta为负数时减少
+func (s *SSearch) IncrCollectCount(ctx context.Context, recipeId int64, delta int64) error {
+	// 更新菜谱的收藏次数，用于按热度排序
+	_, err := g.MongoDB.Database("food").Collection("recipe").
+		UpdateOne(ctx,
+			bson.D{{Key: "recipe_id", Value: recipeId}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "collect_count", Value: delta}}}})
+	if err != nil {
+		g.Logger.Errorf("update [recipe] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// searchCursor 定义分页游标中保存的内容，包括排序字段、最后一条记录的排序值和ID
+type searchCursor struct {
+	Field string      `json:"f"`
+	Value interface{} `json:"v"`
+	Id    string      `json:"id"`
+}
+
+// EncodeCursor 根据排序条件和当前页的最后一个菜谱生成下一页的游标
+func (s *SSearch) EncodeCursor(sort bson.D, last *model.Recipe) string {
+	// 获取排序字段
+	field := sort[0].Key
+
+	// 将排序字段、排序值和ID编码为JSON
+	bytes, _ := json.Marshal(&searchCursor{
+		Field: field,
+		Value: getSortValue(last, field),
+		Id:    last.Id,
+	})
+	// 返回Base64编码后的游标
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// GetCursorFilter 解析游标并生成从游标之后开始查找的过滤条件
+func (s *SSearch) GetCursorFilter(sort bson.D, cursor string) (bson.E, error) {
+	// 相关度得分不能用于过滤，所以不支持游标
+	if sort[0].Key == SortFields["relevance"] {
+		return bson.E{}, fmt.Errorf(`param "cursor" cannot be used with sort by relevance`)
+	}
+
+	// 对游标进行Base64解码，并解析为游标的对象
+	bytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return bson.E{}, fmt.Errorf(`invalid param "cursor"`)
+	}
+	c := &searchCursor{}
+	if err := json.Unmarshal(bytes, c); err != nil || c.Id == "" {
+		return bson.E{}, fmt.Errorf(`invalid param "cursor"`)
+	}
+
+	// 获取排序字段和排序方向
+	field, direction := sort[0].Key, cast.ToInt(sort[0].Value)
+	// 游标中的排序字段必须和当前的排序字段一致
+	if c.Field != field {
+		return bson.E{}, fmt.Errorf(`param "cursor" does not match param "sort"`)
+	}
+
+	// 根据排序方向选择比较运算符
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+
+	// ID可能是ObjectID，也可能是字符串
+	var id interface{} = c.Id
+	if oid, err := primitive.ObjectIDFromHex(c.Id); err == nil {
+		id = oid
+	}
+
+	// 按_id排序时，只需要比较ID
+	if field == "_id" {
+		return bson.E{Key: "_id", Value: bson.D{{Key: op, Value: id}}}, nil
+	}
+
+	// 排序值大于(或小于)游标的值，或者排序值相等但ID大于(或小于)游标的ID
+	return bson.E{
+		Key: "$or",
+		Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: op, Value: c.Value}}}},
+			bson.D{{Key: field, Value: c.Value}, {Key: "_id", Value: bson.D{{Key: op, Value: id}}}},
+		},
+	}, nil
+}
+
+// getSortValue 获取菜谱中排序字段的值
+func getSortValue(recipe *model.Recipe, field string) interface{} {
+	switch field {
+	case "total_time":
+		return recipe.TotalTime
+	case "calories":
+		return recipe.Calories
+	case "protein":
+		return recipe.Protein
+	case "name":
+		return recipe.Name
+	case "collect_count":
+		return recipe.CollectCount
+	case "rating_avg":
+		return recipe.RatingAvg
+	}
+
+	return nil
+}
+
+// GetVisibilityFilter 获取可见性的过滤条件，排除其他用户的私有菜谱
+func (s *SSearch) GetVisibilityFilter(userId int64) bson.E {
+	// 使用$and包裹$or，避免和游标的过滤条件冲突
+	return bson.E{Key: "$and", Value: bson.A{
+		bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "visibility", Value: bson.D{{Key: "$ne", Value: VisibilityPrivate}}}},
+			bson.D{{Key: "owner_id", Value: userId}},
+		}}},
+	}}
+}
@@ -0,0 +1,85 @@
+package recipe
+
+import (
+	"encoding/base64"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"main/app/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	s := &SSearch{}
+	oid := primitive.NewObjectID()
+	last := &model.Recipe{
+		Id:           oid.Hex(),
+		Name:         "Pancakes",
+		Calories:     350.5,
+		CollectCount: 12,
+	}
+
+	tests := []struct {
+		sortKey string
+		order   string
+		want    bson.E
+	}{
+		{"", "", bson.E{Key: "_id", Value: bson.D{{Key: "$gt", Value: oid}}}},
+		{"name", "", bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "name", Value: bson.D{{Key: "$gt", Value: "Pancakes"}}}},
+			bson.D{{Key: "name", Value: "Pancakes"}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: oid}}}},
+		}}},
+		{"calories", "desc", bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "calories", Value: bson.D{{Key: "$lt", Value: 350.5}}}},
+			bson.D{{Key: "calories", Value: 350.5}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: oid}}}},
+		}}},
+		// 游标中的数值经过JSON编码后为float64
+		{"popularity", "", bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "collect_count", Value: bson.D{{Key: "$lt", Value: float64(12)}}}},
+			bson.D{{Key: "collect_count", Value: float64(12)}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: oid}}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortKey+" "+tt.order, func(t *testing.T) {
+			sort, err := s.GetSort(tt.sortKey, tt.order, "")
+			if err != nil {
+				t.Fatalf("GetSort() err = %v", err)
+			}
+			got, err := s.GetCursorFilter(sort, s.EncodeCursor(sort, last))
+			if err != nil {
+				t.Fatalf("GetCursorFilter() err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCursorFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorErrors(t *testing.T) {
+	s := &SSearch{}
+	last := &model.Recipe{Id: primitive.NewObjectID().Hex(), Name: "Pancakes"}
+	byName, _ := s.GetSort("name", "", "")
+	byCalories, _ := s.GetSort("calories", "", "")
+	byRelevance, _ := s.GetSort("relevance", "", "pancakes")
+
+	tests := []struct {
+		name   string
+		sort   bson.D
+		cursor string
+		want   string
+	}{
+		{"relevance", byRelevance, s.EncodeCursor(byName, last), `param "cursor" cannot be used with sort by relevance`},
+		{"other sort", byCalories, s.EncodeCursor(byName, last), `param "cursor" does not match param "sort"`},
+		{"not base64", byName, "!!!", `invalid param "cursor"`},
+		{"not json", byName, base64.RawURLEncoding.EncodeToString([]byte("pancakes")), `invalid param "cursor"`},
+		{"no id", byName, base64.RawURLEncoding.EncodeToString([]byte(`{"f":"name","v":"Pancakes"}`)), `invalid param "cursor"`},
+	}
+
+	for _, tt := range tests {
+		if _, err := s.GetCursorFilter(tt.sort, tt.cursor); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: GetCursorFilter() err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/utils/ingredient"
+	"math"
+	"sort"
+	"strings"
+)
+
+// similarWeights 定义计算相似度时关键词、食材、分类和营养成分的权重，权重之和为1
+var similarWeights = struct {
+	keywords, ingredients, category, nutrition float64
+}{0.35, 0.35, 0.15, 0.15}
+
+// similarCandidates 计算相似度时最多比较的候选菜谱数量
+const similarCandidates = 1000
+
+// SimilarRecipes 查找和给定的菜谱相似的菜谱，按相似度降序排列
+// 候选菜谱是符合过滤条件并且分类相同或者有相同关键词的菜谱，相似度根据关键词、食材、分类和营养成分计算
+func (s *SSearch) SimilarRecipes(ctx context.Context, recipe *model.Recipe, filter bson.D, limit int) ([]*model.RecipeScore, error) {
+	// 分类相同或者有相同关键词的菜谱
+	var or bson.A
+	if recipe.Category != "" {
+		or = append(or, bson.D{{Key: "category", Value: recipe.Category}})
+	}
+	if len(recipe.Keywords) > 0 {
+		or = append(or, bson.D{{Key: "keywords", Value: bson.D{{Key: "$in", Value: recipe.Keywords}}}})
+	}
+	if len(or) == 0 {
+		return []*model.RecipeScore{}, nil
+	}
+
+	// 排除菜谱本身
+	match := append(bson.D{
+		{Key: "recipe_id", Value: bson.D{{Key: "$ne", Value: recipe.RecipeId}}},
+		{Key: "$or", Value: or},
+	}, filter...)
+
+	// 在数据库中查找候选菜谱，收藏次数多的优先
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Find(ctx, match, options.Find().
+			SetSort(bson.D{{Key: "collect_count", Value: -1}, {Key: "_id", Value: 1}}).
+			SetLimit(similarCandidates))
+	if err != nil {
+		g.Logger.Errorf("query [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 将查找的结果解码为菜谱的列表
+	var candidates []*model.Recipe
+	if err = cur.All(ctx, &candidates); err != nil {
+		g.Logger.Errorf("decode [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 计算每一个候选菜谱的相似度
+	profile := newSimilarProfile(recipe)
+	res := make([]*model.RecipeScore, 0, len(candidates))
+	for _, candidate := range candidates {
+		res = append(res, &model.RecipeScore{
+			Recipe: candidate,
+			Score:  math.Round(profile.score(newSimilarProfile(candidate))*1000) / 1000,
+		})
+	}
+
+	// 按相似度降序排列，相似度相同时按菜谱ID升序排列
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Recipe.RecipeId < res[j].Recipe.RecipeId
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+// similarProfile 定义计算相似度使用的菜谱特征
+type similarProfile struct {
+	keywords    map[string]bool
+	ingredients map[string]bool
+	category    string
+	macros      [3]float64 // 蛋白质、脂肪和碳水化合物的热量占比
+	hasMacros   bool
+}
+
+// newSimilarProfile 获取菜谱的特征
+func newSimilarProfile(recipe *model.Recipe) *similarProfile {
+	p := &similarProfile{
+		keywords:    make(map[string]bool, len(recipe.Keywords)),
+		ingredients: make(map[string]bool, len(recipe.Ingredients)),
+		category:    strings.ToLower(strings.TrimSpace(recipe.Category)),
+	}
+	for _, keyword := range recipe.Keywords {
+		p.keywords[strings.ToLower(strings.TrimSpace(keyword))] = true
+	}
+	for _, item := range ingredient.ParseAll(recipe.Ingredients) {
+		if name := ingredient.NormalizeName(item.Name); name != "" {
+			p.ingredients[name] = true
+		}
+	}
+
+	// 蛋白质和碳水化合物每克4千卡，脂肪每克9千卡
+	protein, fat, carbohydrate := recipe.Protein*4, recipe.Fat*9, recipe.Carbohydrate*4
+	if total := protein + fat + carbohydrate; total > 0 {
+		p.macros = [3]float64{protein / total, fat / total, carbohydrate / total}
+		p.hasMacros = true
+	}
+
+	return p
+}
+
+// score 计算两个菜谱特征的相似度
+func (p *similarProfile) score(o *similarProfile) float64 {
+	res := similarWeights.keywords*jaccard(p.keywords, o.keywords) +
+		similarWeights.ingredients*jaccard(p.ingredients, o.ingredients)
+	if p.category != "" && p.category == o.category {
+		res += similarWeights.category
+	}
+
+	// 营养成分的相似度为1减去热量占比的差的绝对值之和的一半
+	if p.hasMacros && o.hasMacros {
+		var diff float64
+		for i := range p.macros {
+			diff += math.Abs(p.macros[i] - o.macros[i])
+		}
+		res += similarWeights.nutrition * (1 - diff/2)
+	}
+
+	return res
+}
+
+// jaccard 计算两个集合的Jaccard相似系数
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var inter int
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
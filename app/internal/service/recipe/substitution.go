@@ -0,0 +1,149 @@
+package recipe
+
+import (
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/app/internal/model/config"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// Diets 定义可以查询替换建议的饮食习惯
+var Diets = map[string]bool{
+	"vegan":      true,
+	"vegetarian": true,
+	"halal":      true,
+}
+
+// meatPattern 匹配肉类和动物性的食材
+const meatPattern = `\b(meat|beef|steak|veal|pork|ham|bacon|pancetta|prosciutto|salami|pepperoni|chorizo|sausage|lamb|mutton|goat|venison|rabbit|chicken|turkey|duck|goose|brisket|mince|ribs?|lard|suet|tallow|gelatine?)\b`
+
+// DietPatterns 定义不符合饮食习惯的食材的正则表达式，知识库中没有规则的部分使用它检查
+// 和过敏原一样宁可多报告
+var DietPatterns = map[string]string{
+	"vegetarian": meatPattern + "|" + Allergens["fish"] + "|" + Allergens["shellfish"],
+	"vegan": meatPattern + "|" + Allergens["fish"] + "|" + Allergens["shellfish"] + "|" +
+		Allergens["milk"] + "|" + Allergens["egg"] + `|\bhoney\b`,
+	"halal": `\b(pork|ham|bacon|pancetta|prosciutto|salami|pepperoni|chorizo|lard|gelatine?|wine|beer|rum|brandy|whisk(e)?y|vodka|liqueur|sake|mirin)\b`,
+}
+
+// SSubstitution 定义一个食材替换的结构体
+type SSubstitution struct {
+	once      sync.Once
+	rules     []*substitutionRule
+	allergens map[string]*regexp.Regexp
+	diets     map[string]*regexp.Regexp
+}
+
+// substitutionRule 定义编译后的替换规则
+type substitutionRule struct {
+	*config.SubstitutionRule
+	re *regexp.Regexp
+}
+
+// GetSubstitutions 为菜谱中不符合饮食习惯或者含有过敏原的食材提供替换建议
+// 每个食材使用知识库中第一个匹配的规则，规则匹配的部分使用规则中的饮食习惯和过敏原，
+// 食材的其余部分再使用饮食习惯和过敏原的正则表达式检查，避免知识库不完整时漏掉过敏原
+func (s *SSubstitution) GetSubstitutions(recipe *model.Recipe, diet string, allergens []string) []*model.IngredientSubstitution {
+	s.once.Do(s.compile)
+
+	res := []*model.IngredientSubstitution{}
+	for i, ingredient := range recipe.Ingredients {
+		item := &model.IngredientSubstitution{
+			Index:        i,
+			Ingredient:   ingredient,
+			Reasons:      []string{},
+			Replacements: []*model.SubstituteOption{},
+		}
+
+		// 去掉规则匹配的部分，剩下的部分使用正则表达式检查
+		rule, loc := s.matchRule(ingredient)
+		rest := ingredient
+		if rule != nil {
+			item.Rule = rule.Name
+			rest = ingredient[:loc[0]] + " " + ingredient[loc[1]:]
+		}
+
+		// 食材不符合饮食习惯
+		if diet != "" {
+			if (rule != nil && slices.Contains(rule.Diets, diet)) ||
+				(s.diets[diet] != nil && s.diets[diet].MatchString(rest)) {
+				item.Reasons = append(item.Reasons, diet)
+			}
+		}
+		// 食材含有用户的过敏原
+		for _, allergen := range allergens {
+			if (rule != nil && slices.Contains(rule.Allergens, allergen)) ||
+				(s.allergens[allergen] != nil && s.allergens[allergen].MatchString(rest)) {
+				item.Reasons = append(item.Reasons, allergen)
+			}
+		}
+		if len(item.Reasons) == 0 {
+			continue
+		}
+
+		// 排除同样不符合饮食习惯或者含有用户过敏原的替换
+		if rule != nil {
+			for _, r := range rule.Replacements {
+				if (diet != "" && slices.Contains(r.Diets, diet)) || slices.ContainsFunc(r.Allergens, func(a string) bool {
+					return slices.Contains(allergens, a)
+				}) {
+					continue
+				}
+				item.Replacements = append(item.Replacements, &model.SubstituteOption{
+					Name:  r.Name,
+					Ratio: r.Ratio,
+					Note:  r.Note,
+				})
+			}
+		}
+
+		res = append(res, item)
+	}
+
+	return res
+}
+
+// matchRule 获取第一个匹配食材的规则和匹配的位置，没有匹配的规则时返回nil
+func (s *SSubstitution) matchRule(ingredient string) (*substitutionRule, []int) {
+	for _, rule := range s.rules {
+		if loc := rule.re.FindStringIndex(ingredient); loc != nil {
+			return rule, loc
+		}
+	}
+
+	return nil, nil
+}
+
+// compile 编译知识库中的规则和过敏原的正则表达式，无效的规则只记录日志
+func (s *SSubstitution) compile() {
+	s.allergens = make(map[string]*regexp.Regexp, len(Allergens))
+	for name, pattern := range Allergens {
+		s.allergens[name] = regexp.MustCompile("(?i)" + pattern)
+	}
+	s.diets = make(map[string]*regexp.Regexp, len(DietPatterns))
+	for name, pattern := range DietPatterns {
+		s.diets[name] = regexp.MustCompile("(?i)" + pattern)
+	}
+
+	if g.Substitution == nil {
+		return
+	}
+	for _, rule := range g.Substitution.Rules {
+		if rule == nil || rule.Name == "" {
+			continue
+		}
+		// 没有正则表达式时按名称匹配
+		pattern := rule.Pattern
+		if pattern == "" {
+			pattern = `\b` + regexp.QuoteMeta(rule.Name) + `\b`
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			g.Logger.Errorf("compile substitution rule [%s] failed, err: %v", rule.Name, err)
+			continue
+		}
+		s.rules = append(s.rules, &substitutionRule{SubstitutionRule: rule, re: re})
+	}
+}
@@ -0,0 +1,116 @@
+package recipe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	g "main/app/global"
+	"main/app/internal/model"
+	"time"
+)
+
+// TermFields 定义可以浏览的字段，键为接口的名称，值为菜谱的字段
+var TermFields = map[string]string{
+	"categories": "category",
+	"keywords":   "keywords",
+}
+
+// 定义统计结果的缓存时间和刷新间隔，缓存时间大于刷新间隔，刷新失败时仍然可以使用缓存
+const (
+	termsCacheExpire     = 2 * time.Hour
+	termsRefreshInterval = time.Hour
+	termsMaxCount        = 1000
+)
+
+// GetTerms 获取公开菜谱中字段的所有值和对应的菜谱数量，按数量从大到小排序
+// 优先从Redis中读取，缓存不存在时从数据库中统计并写入缓存
+func (s *SSearch) GetTerms(ctx context.Context, field string) ([]*model.RecipeFacet, error) {
+	val, err := g.Rdb.Get(ctx, termsCacheKey(field)).Result()
+	if err == nil {
+		var terms []*model.RecipeFacet
+		if err = json.Unmarshal([]byte(val), &terms); err == nil {
+			return terms, nil
+		}
+		g.Logger.Errorf("decode [terms] cache failed, err: %v", err)
+	} else if !errors.Is(err, redis.Nil) {
+		// Redis出现错误时直接从数据库中统计
+		g.Logger.Errorf("get [terms] cache failed, err: %v", err)
+	}
+
+	return s.RefreshTerms(ctx, field)
+}
+
+// RefreshTerms 从数据库中统计字段的值和菜谱数量，并更新Redis中的缓存
+func (s *SSearch) RefreshTerms(ctx context.Context, field string) ([]*model.RecipeFacet, error) {
+	// 只统计公开的菜谱，数组字段需要先展开，并忽略空值
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "visibility", Value: bson.D{{Key: "$ne", Value: VisibilityPrivate}}},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$" + field}},
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: field, Value: bson.D{{Key: "$nin", Value: bson.A{"", nil}}}},
+		}}},
+		bson.D{{Key: "$sortByCount", Value: "$" + field}},
+		bson.D{{Key: "$limit", Value: termsMaxCount}},
+	}
+	cur, err := g.MongoDB.Database("food").Collection("recipe").
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		g.Logger.Errorf("aggregate [recipe] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	var docs []bson.M
+	if err = cur.All(ctx, &docs); err != nil {
+		g.Logger.Errorf("decode [recipe] terms failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	terms := make([]*model.RecipeFacet, 0, len(docs))
+	for _, doc := range docs {
+		terms = append(terms, toFacet(field, doc))
+	}
+
+	// 写入缓存失败时只记录日志
+	bytes, _ := json.Marshal(terms)
+	if err = g.Rdb.Set(ctx, termsCacheKey(field), bytes, termsCacheExpire).Err(); err != nil {
+		g.Logger.Errorf("set [terms] cache failed, err: %v", err)
+	}
+
+	return terms, nil
+}
+
+// RefreshTermsPeriodically 在后台定时刷新所有字段的统计缓存，直到ctx被取消
+func (s *SSearch) RefreshTermsPeriodically(ctx context.Context) {
+	refresh := func() {
+		for _, field := range TermFields {
+			if _, err := s.RefreshTerms(ctx, field); err != nil {
+				g.Logger.Errorf("refresh [%s] terms failed, err: %v", field, err)
+			}
+		}
+	}
+
+	go func() {
+		refresh()
+
+		ticker := time.NewTicker(termsRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// termsCacheKey 获取字段统计结果在Redis中的键
+func termsCacheKey(field string) string {
+	return fmt.Sprintf("recipe_terms_%s", field)
+}
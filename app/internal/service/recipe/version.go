@@ -0,0 +1,264 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	g "main/app/global"
+	"main/app/internal/model"
+	"main/utils/diff"
+	"slices"
+	"time"
+)
+
+// SVersion 定义一个菜谱版本历史的结构体
+type SVersion struct{}
+
+// 定义产生版本的操作
+const (
+	ActionOriginal = "original" // 没有历史记录的菜谱在第一次修改前的内容
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionFork     = "fork"
+	ActionRevert   = "revert"
+	ActionImages   = "images"
+)
+
+// RecordVersion 保存菜谱当前版本的快照
+func (s *SVersion) RecordVersion(ctx context.Context, recipe *model.Recipe, action string) error {
+	_, err := g.MongoDB.Database("food").Collection("recipe_version").
+		InsertOne(ctx, newVersion(recipe, action, time.Now()))
+	if err != nil {
+		g.Logger.Errorf("insert [recipe_version] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// EnsureVersion 修改菜谱前检查当前版本是否有快照，没有时保存当前的内容，保证可以恢复到修改前的版本
+func (s *SVersion) EnsureVersion(ctx context.Context, recipe *model.Recipe) error {
+	version := newVersion(recipe, ActionOriginal, time.Now())
+	_, err := g.MongoDB.Database("food").Collection("recipe_version").
+		UpdateOne(ctx,
+			bson.D{
+				{Key: "recipe_id", Value: version.RecipeId},
+				{Key: "version", Value: version.Version},
+			},
+			bson.D{{Key: "$setOnInsert", Value: version}},
+			options.Update().SetUpsert(true))
+	if err != nil {
+		g.Logger.Errorf("upsert [recipe_version] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// GetVersion 获取菜谱的一个版本，包括菜谱的快照
+func (s *SVersion) GetVersion(ctx context.Context, recipeId, version int64) (*model.RecipeVersion, error) {
+	res := &model.RecipeVersion{}
+	err := g.MongoDB.Database("food").Collection("recipe_version").
+		FindOne(ctx, bson.D{
+			{Key: "recipe_id", Value: recipeId},
+			{Key: "version", Value: version},
+		}).
+		Decode(res)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("version not found")
+		}
+		g.Logger.Errorf("find [recipe_version] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return res, nil
+}
+
+// GetVersionCount 获取菜谱的版本数量
+func (s *SVersion) GetVersionCount(ctx context.Context, recipeId int64) (int64, error) {
+	cnt, err := g.MongoDB.Database("food").Collection("recipe_version").
+		CountDocuments(ctx, bson.D{{Key: "recipe_id", Value: recipeId}})
+	if err != nil {
+		g.Logger.Errorf("count [recipe_version] document failed, err: %v", err)
+		return 0, fmt.Errorf("internal err")
+	}
+
+	return cnt, nil
+}
+
+// GetVersionsWithLimit 分页获取菜谱的版本，按版本号从新到旧排序，不包括菜谱的快照
+func (s *SVersion) GetVersionsWithLimit(ctx context.Context, recipeId int64, limit, page int) ([]*model.RecipeVersion, error) {
+	cursor, err := g.MongoDB.Database("food").Collection("recipe_version").
+		Find(ctx,
+			bson.D{{Key: "recipe_id", Value: recipeId}},
+			options.Find().
+				SetProjection(bson.D{{Key: "recipe", Value: 0}}).
+				SetSort(bson.D{{Key: "version", Value: -1}}).
+				SetSkip(int64((page-1)*limit)).
+				SetLimit(int64(limit)))
+	if err != nil {
+		g.Logger.Errorf("find [recipe_version] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	versions := []*model.RecipeVersion{}
+	if err = cursor.All(ctx, &versions); err != nil {
+		g.Logger.Errorf("decode [recipe_version] document failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return versions, nil
+}
+
+// GetVersionImages 获取菜谱所有版本中用到的图片的URL
+func (s *SVersion) GetVersionImages(ctx context.Context, recipeId int64) ([]string, error) {
+	values, err := g.MongoDB.Database("food").Collection("recipe_version").
+		Distinct(ctx, "recipe.images", bson.D{{Key: "recipe_id", Value: recipeId}})
+	if err != nil {
+		g.Logger.Errorf("distinct [recipe_version] images failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	urls := make([]string, 0, len(values))
+	for _, value := range values {
+		if u, ok := value.(string); ok {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls, nil
+}
+
+// DeleteVersions 删除菜谱的所有版本，失败时只记录日志
+func (s *SVersion) DeleteVersions(ctx context.Context, recipeId int64) error {
+	_, err := g.MongoDB.Database("food").Collection("recipe_version").
+		DeleteMany(ctx, bson.D{{Key: "recipe_id", Value: recipeId}})
+	if err != nil {
+		g.Logger.Errorf("delete [recipe_version] document failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// CurrentVersion 将菜谱当前的内容转换为一个版本，用于和历史版本比较，不保存到数据库
+func (s *SVersion) CurrentVersion(recipe *model.Recipe) *model.RecipeVersion {
+	return newVersion(recipe, "", recipe.UpdateTime)
+}
+
+// DiffVersions 比较菜谱的两个版本，返回用户可以编辑的字段的变化，列表字段给出逐行的差异
+func (s *SVersion) DiffVersions(from, to *model.RecipeVersion) *model.RecipeDiff {
+	res := &model.RecipeDiff{
+		RecipeId: to.RecipeId,
+		From:     from.Version,
+		To:       to.Version,
+		Changes:  []*model.RecipeChange{},
+	}
+
+	a, b := recipeForm(from.Recipe), recipeForm(to.Recipe)
+
+	// 比较列表字段
+	for _, field := range []struct {
+		name     string
+		old, new []string
+	}{
+		{"images", a.Images, b.Images},
+		{"dietary", a.Dietary, b.Dietary},
+		{"keywords", a.Keywords, b.Keywords},
+		{"ingredients", a.Ingredients, b.Ingredients},
+		{"instruction", a.Instruction, b.Instruction},
+	} {
+		if slices.Equal(field.old, field.new) {
+			continue
+		}
+		res.Changes = append(res.Changes, &model.RecipeChange{
+			Field: field.name,
+			Lines: diff.Lines(field.old, field.new),
+		})
+	}
+
+	// 比较其他字段
+	for _, field := range []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"name", a.Name, b.Name},
+		{"category", a.Category, b.Category},
+		{"description", a.Description, b.Description},
+		{"servings", a.Servings, b.Servings},
+		{"cook_time", a.CookTime, b.CookTime},
+		{"perp_time", a.PerpTime, b.PerpTime},
+		{"total_time", a.TotalTime, b.TotalTime},
+		{"calories", a.Calories, b.Calories},
+		{"fat", a.Fat, b.Fat},
+		{"saturated_fat", a.SaturatedFat, b.SaturatedFat},
+		{"sodium", a.Sodium, b.Sodium},
+		{"carbohydrate", a.Carbohydrate, b.Carbohydrate},
+		{"fiber", a.Fiber, b.Fiber},
+		{"sugar", a.Sugar, b.Sugar},
+		{"protein", a.Protein, b.Protein},
+		{"visibility", a.Visibility, b.Visibility},
+	} {
+		if field.old == field.new {
+			continue
+		}
+		res.Changes = append(res.Changes, &model.RecipeChange{
+			Field: field.name,
+			Old:   field.old,
+			New:   field.new,
+		})
+	}
+
+	return res
+}
+
+// newVersion 创建菜谱当前版本的快照，不保存文档ID和全文搜索的得分
+func newVersion(recipe *model.Recipe, action string, now time.Time) *model.RecipeVersion {
+	snapshot := *recipe
+	snapshot.Id = ""
+	snapshot.Score = 0
+
+	return &model.RecipeVersion{
+		RecipeId:   recipe.RecipeId,
+		Version:    recipe.Version,
+		Action:     action,
+		UserId:     recipe.OwnerId,
+		Recipe:     &snapshot,
+		CreateTime: now,
+	}
+}
+
+// recipeForm 将菜谱中用户可以编辑的内容转换为菜谱的表单
+func recipeForm(recipe *model.Recipe) *model.RecipeForm {
+	if recipe == nil {
+		return &model.RecipeForm{}
+	}
+
+	return &model.RecipeForm{
+		Images:       recipe.Images,
+		Name:         recipe.Name,
+		Category:     recipe.Category,
+		Dietary:      recipe.Dietary,
+		Description:  recipe.Description,
+		Keywords:     recipe.Keywords,
+		Instruction:  recipe.Instruction,
+		Ingredients:  recipe.Ingredients,
+		Servings:     recipe.Servings,
+		CookTime:     recipe.CookTime,
+		PerpTime:     recipe.PerpTime,
+		TotalTime:    recipe.TotalTime,
+		Calories:     recipe.Calories,
+		Fat:          recipe.Fat,
+		SaturatedFat: recipe.SaturatedFat,
+		Sodium:       recipe.Sodium,
+		Carbohydrate: recipe.Carbohydrate,
+		Fiber:        recipe.Fiber,
+		Sugar:        recipe.Sugar,
+		Protein:      recipe.Protein,
+		Visibility:   recipe.Visibility,
+	}
+}
@@ -36,31 +36,41 @@ func (s *SCollect) CheckCollectionIsExist(ctx context.Context, collectType int32
 	return nil
 }
 
-// CheckCollectionIdIsExist 检查给定的收藏ID是否存在
-func (s *SCollect) CheckCollectionIdIsExist(ctx context.Context, id, userId int64) error {
+// CheckCollectionIdIsExist 检查给定的收藏ID是否存在，存在时返回这个收藏
+func (s *SCollect) CheckCollectionIdIsExist(ctx context.Context, id, userId int64) (*model.UserCollection, error) {
 	// 在数据库中查找是否存在一个ID和用户ID都匹配的收藏
-	err := dao.User().Collect().GetCollectionById(ctx, id, userId)
+	userCollection, err := dao.User().Collect().GetCollectionById(ctx, id, userId)
 	// 如果查找过程中出现错误
 	if err != nil {
 		// 如果错误是因为找不到记录
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 返回收藏不存在的错误
-			return fmt.Errorf("collection not found")
+			return nil, fmt.Errorf("collection not found")
 		}
 		// 记录错误日志
 		g.Logger.Errorf("query [user_collection] record failed, err: %v", err)
 		// 返回内部错误
-		return fmt.Errorf("internal err")
+		return nil, fmt.Errorf("internal err")
 	}
 
-	// 如果没有错误，返回nil
-	return nil
+	// 如果没有错误，返回收藏
+	return userCollection, nil
 }
 
 // CreateCollection 在数据库中创建一个新的收藏
-func (s *SCollect) CreateCollection(ctx context.Context, userCollection *model.UserCollection) {
+func (s *SCollect) CreateCollection(ctx context.Context, userCollection *model.UserCollection) error {
 	// 在数据库中创建收藏
-	dao.User().Collect().CreateCollection(ctx, userCollection)
+	err := dao.User().Collect().CreateCollection(ctx, userCollection)
+	// 如果创建过程中出现错误
+	if err != nil {
+		// 记录错误日志
+		g.Logger.Errorf("create [user_collection] record failed, err: %v", err)
+		// 返回内部错误
+		return fmt.Errorf("internal err")
+	}
+
+	// 如果没有错误，返回nil
+	return nil
 }
 
 // DeleteCollection 在数据库中删除一个收藏
@@ -79,6 +89,16 @@ func (s *SCollect) DeleteCollection(ctx context.Context, id int64) error {
 	return nil
 }
 
+// DeleteRecipeCollections 删除所有用户对一个菜谱的收藏，在菜谱被删除时调用
+func (s *SCollect) DeleteRecipeCollections(ctx context.Context, recipeId int64) error {
+	if err := dao.User().Collect().DeleteRecipeCollections(ctx, recipeId); err != nil {
+		g.Logger.Errorf("delete [user_collection] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
 // GetUserCollectionCount 获取用户的收藏数量
 func (s *SCollect) GetUserCollectionCount(ctx context.Context, userId int64, collectType int32) (int64, error) {
 	// 在数据库中计算这个用户的这种类型的收藏的数量
@@ -110,3 +130,39 @@ func (s *SCollect) GetUserCollectionsWithLimit(ctx context.Context, userId int64
 	// 如果没有错误，返回收藏的列表
 	return userCollections, nil
 }
+
+// GetRecipeCollection 获取用户对某个菜谱的收藏，未收藏时返回nil
+func (s *SCollect) GetRecipeCollection(ctx context.Context, userId, recipeId int64) (*model.UserCollection, error) {
+	// 在数据库中查找这个用户对这个菜谱的收藏
+	userCollection, err := dao.User().Collect().GetRecipeCollection(ctx, userId, recipeId)
+	// 如果查找过程中出现错误
+	if err != nil {
+		// 如果错误是因为找不到记录，说明用户没有收藏这个菜谱
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		// 记录错误日志
+		g.Logger.Errorf("query [user_collection] record failed, err: %v", err)
+		// 返回内部错误
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 如果没有错误，返回收藏
+	return userCollection, nil
+}
+
+// GetUserRecipeIds 获取用户最近收藏的菜谱ID
+func (s *SCollect) GetUserRecipeIds(ctx context.Context, userId int64, limit int) ([]int64, error) {
+	// 在数据库中查找这个用户最近收藏的菜谱ID
+	recipeIds, err := dao.User().Collect().GetUserRecipeIds(ctx, userId, limit)
+	// 如果查找过程中出现错误
+	if err != nil {
+		// 记录错误日志
+		g.Logger.Errorf("query [user_collection] record failed, err: %v", err)
+		// 返回内部错误
+		return nil, fmt.Errorf("internal err")
+	}
+
+	// 如果没有错误，返回菜谱ID的列表
+	return recipeIds, nil
+}
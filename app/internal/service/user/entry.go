@@ -15,3 +15,17 @@ var insCollect = SCollect{}
 func (g *Group) Collect() *SCollect {
 	return &insCollect
 }
+
+// insPlan 创建一个膳食计划的实例
+var insPlan = SPlan{}
+
+func (g *Group) Plan() *SPlan {
+	return &insPlan
+}
+
+// insShopping 创建一个购物清单的实例
+var insShopping = SShopping{}
+
+func (g *Group) Shopping() *SShopping {
+	return &insShopping
+}
@@ -0,0 +1,273 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	g "main/app/global"
+	"main/app/internal/dao"
+	"main/app/internal/model"
+	"math"
+	"slices"
+	"time"
+)
+
+// SPlan 定义一个膳食计划的结构体，用于处理膳食计划相关的操作
+type SPlan struct{}
+
+// DateLayout 定义膳食计划中日期的格式
+const DateLayout = "2006-01-02"
+
+// Meals 定义膳食计划的餐次，按一天中的顺序排列
+var Meals = []string{"breakfast", "lunch", "dinner", "snack"}
+
+// ParseDate 解析膳食计划的日期，为空时使用今天
+func (s *SPlan) ParseDate(date string) (time.Time, error) {
+	if date == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date")
+	}
+	return t, nil
+}
+
+// WeekStart 获取日期所在的一周的第一天，一周从星期一开始
+func (s *SPlan) WeekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// ValidatePlan 检查膳食计划是否有效，份数为空时默认为1份
+func (s *SPlan) ValidatePlan(mealPlan *model.MealPlan) error {
+	if _, err := time.Parse(DateLayout, mealPlan.PlanDate); err != nil {
+		return fmt.Errorf("invalid date")
+	}
+	if !slices.Contains(Meals, mealPlan.Meal) {
+		return fmt.Errorf("invalid meal")
+	}
+	if mealPlan.RecipeId <= 0 {
+		return fmt.Errorf("recipe_id cannot be null")
+	}
+	if mealPlan.Servings == 0 {
+		mealPlan.Servings = 1
+	}
+	if mealPlan.Servings < 0 || mealPlan.Servings > 100 {
+		return fmt.Errorf("invalid servings")
+	}
+
+	return nil
+}
+
+// GetPlan 获取用户的一项膳食计划
+func (s *SPlan) GetPlan(ctx context.Context, id, userId int64) (*model.MealPlan, error) {
+	mealPlan, err := dao.User().Plan().GetPlanById(ctx, id, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("meal plan not found")
+		}
+		g.Logger.Errorf("query [meal_plan] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return mealPlan, nil
+}
+
+// GetPlans 获取用户在日期范围内的膳食计划，包括开始和结束的日期
+func (s *SPlan) GetPlans(ctx context.Context, userId int64, from, to time.Time) ([]*model.MealPlan, error) {
+	mealPlans, err := dao.User().Plan().GetPlansByDate(ctx, userId, from.Format(DateLayout), to.Format(DateLayout))
+	if err != nil {
+		g.Logger.Errorf("query [meal_plan] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return mealPlans, nil
+}
+
+// CreatePlan 创建一项膳食计划
+func (s *SPlan) CreatePlan(ctx context.Context, mealPlan *model.MealPlan) error {
+	if err := dao.User().Plan().CreatePlan(ctx, mealPlan); err != nil {
+		g.Logger.Errorf("create [meal_plan] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// UpdatePlan 更新一项膳食计划
+func (s *SPlan) UpdatePlan(ctx context.Context, mealPlan *model.MealPlan) error {
+	if err := dao.User().Plan().UpdatePlan(ctx, mealPlan); err != nil {
+		g.Logger.Errorf("update [meal_plan] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// DeletePlan 删除一项膳食计划
+func (s *SPlan) DeletePlan(ctx context.Context, id, userId int64) error {
+	if err := dao.User().Plan().DeletePlan(ctx, id, userId); err != nil {
+		g.Logger.Errorf("delete [meal_plan] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// DeletePlansByRecipe 删除所有用户安排了一个菜谱的膳食计划，在菜谱被删除时调用
+func (s *SPlan) DeletePlansByRecipe(ctx context.Context, recipeId int64) error {
+	if err := dao.User().Plan().DeletePlansByRecipe(ctx, recipeId); err != nil {
+		g.Logger.Errorf("delete [meal_plan] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// CopyWeek 将一周的膳食计划复制到另一周，返回复制的数量
+// replace为true时在一个事务中清空目标周的膳食计划并复制，否则跳过目标周已有的相同日期、餐次和菜谱的膳食计划
+func (s *SPlan) CopyWeek(ctx context.Context, userId int64, from, to time.Time, replace bool) (int, error) {
+	from, to = s.WeekStart(from), s.WeekStart(to)
+	if from.Equal(to) {
+		return 0, fmt.Errorf("cannot copy a week to itself")
+	}
+
+	// 获取源周的膳食计划
+	mealPlans, err := s.GetPlans(ctx, userId, from, from.AddDate(0, 0, 6))
+	if err != nil {
+		return 0, err
+	}
+
+	// 不替换时，记录目标周已有的膳食计划，避免重复复制
+	existing := make(map[string]bool)
+	if !replace {
+		planned, err := s.GetPlans(ctx, userId, to, to.AddDate(0, 0, 6))
+		if err != nil {
+			return 0, err
+		}
+		for _, mealPlan := range planned {
+			existing[planKey(mealPlan)] = true
+		}
+	}
+
+	// 按照相同的星期几复制到目标周
+	days := int(to.Sub(from).Hours() / 24)
+	copies := make([]*model.MealPlan, 0, len(mealPlans))
+	for _, mealPlan := range mealPlans {
+		date, _ := time.Parse(DateLayout, mealPlan.PlanDate)
+		copied := &model.MealPlan{
+			UserId:   userId,
+			PlanDate: date.AddDate(0, 0, days).Format(DateLayout),
+			Meal:     mealPlan.Meal,
+			RecipeId: mealPlan.RecipeId,
+			Servings: mealPlan.Servings,
+		}
+		if !replace {
+			if existing[planKey(copied)] {
+				continue
+			}
+			existing[planKey(copied)] = true
+		}
+		copies = append(copies, copied)
+	}
+
+	// 替换时，清空目标周和复制在同一个事务中完成，失败时目标周保持不变
+	if replace {
+		err = dao.User().Plan().ReplacePlansByDate(ctx, userId,
+			to.Format(DateLayout), to.AddDate(0, 0, 6).Format(DateLayout), copies)
+		if err != nil {
+			g.Logger.Errorf("replace [meal_plan] record failed, err: %v", err)
+			return 0, fmt.Errorf("internal err")
+		}
+		return len(copies), nil
+	}
+
+	if len(copies) == 0 {
+		return 0, nil
+	}
+	if err = dao.User().Plan().CreatePlans(ctx, copies); err != nil {
+		g.Logger.Errorf("create [meal_plan] record failed, err: %v", err)
+		return 0, fmt.Errorf("internal err")
+	}
+
+	return len(copies), nil
+}
+
+// planKey 返回膳食计划的日期、餐次和菜谱组成的键，用于判断膳食计划是否重复
+func planKey(mealPlan *model.MealPlan) string {
+	return fmt.Sprintf("%s/%s/%d", mealPlan.PlanDate, mealPlan.Meal, mealPlan.RecipeId)
+}
+
+// BuildWeek 将一周的膳食计划按天分组，并计算每一天营养成分的合计
+// 菜谱的营养成分对应菜谱的份数，会按照膳食计划的份数缩放，没有记录份数的菜谱视为1份
+func (s *SPlan) BuildWeek(start time.Time, mealPlans []*model.MealPlan, recipes map[int64]*model.Recipe) []*model.MealPlanDay {
+	days := make([]*model.MealPlanDay, 7)
+	index := make(map[string]*model.MealPlanDay, 7)
+	for i := range days {
+		date := start.AddDate(0, 0, i).Format(DateLayout)
+		days[i] = &model.MealPlanDay{
+			Date:      date,
+			Meals:     []*model.MealPlanEntry{},
+			Nutrition: &model.Nutrition{},
+		}
+		index[date] = days[i]
+	}
+
+	for _, mealPlan := range mealPlans {
+		day, ok := index[mealPlan.PlanDate]
+		if !ok {
+			continue
+		}
+
+		recipe := recipes[mealPlan.RecipeId]
+		day.Meals = append(day.Meals, &model.MealPlanEntry{
+			Id:       mealPlan.Id,
+			Meal:     mealPlan.Meal,
+			RecipeId: mealPlan.RecipeId,
+			Servings: mealPlan.Servings,
+			Recipe:   recipe,
+		})
+		if recipe != nil {
+			addNutrition(day.Nutrition, recipe, mealPlan.Servings)
+		}
+	}
+
+	// 每一天按餐次排序，并将营养成分的合计保留一位小数
+	for _, day := range days {
+		slices.SortStableFunc(day.Meals, func(a, b *model.MealPlanEntry) int {
+			return slices.Index(Meals, a.Meal) - slices.Index(Meals, b.Meal)
+		})
+		roundNutrition(day.Nutrition)
+	}
+
+	return days
+}
+
+// addNutrition 将菜谱按份数缩放后的营养成分加到合计中
+func addNutrition(n *model.Nutrition, recipe *model.Recipe, servings int64) {
+	base := recipe.Servings
+	if base <= 0 {
+		base = 1
+	}
+	factor := float64(servings) / float64(base)
+
+	n.Calories += recipe.Calories * factor
+	n.Fat += recipe.Fat * factor
+	n.SaturatedFat += recipe.SaturatedFat * factor
+	n.Sodium += recipe.Sodium * factor
+	n.Carbohydrate += recipe.Carbohydrate * factor
+	n.Fiber += recipe.Fiber * factor
+	n.Sugar += recipe.Sugar * factor
+	n.Protein += recipe.Protein * factor
+}
+
+// roundNutrition 将营养成分保留一位小数
+func roundNutrition(n *model.Nutrition) {
+	for _, v := range []*float64{&n.Calories, &n.Fat, &n.SaturatedFat, &n.Sodium,
+		&n.Carbohydrate, &n.Fiber, &n.Sugar, &n.Protein} {
+		*v = math.Round(*v*10) / 10
+	}
+}
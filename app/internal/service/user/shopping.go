@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	g "main/app/global"
+	"main/app/internal/dao"
+	"main/app/internal/model"
+	"main/utils/ingredient"
+	"main/utils/unit"
+)
+
+// SShopping 定义一个购物清单的结构体，用于处理购物清单相关的操作
+type SShopping struct{}
+
+// ShoppingSource 定义生成购物清单的一个菜谱和需要的份数，份数为0时使用菜谱的份数
+type ShoppingSource struct {
+	Recipe   *model.Recipe
+	Servings int64
+}
+
+// shoppingKey 定义合并购物清单项的键，名称和规范化后的单位都相同的食材会合并
+type shoppingKey struct {
+	name string
+	unit string
+}
+
+// BuildItems 根据菜谱生成购物清单项，合并相同的食材
+// 体积和重量会统一换算后再合并，然后使用指定的单位制输出，单位制为空时公制的食材使用公制，其他使用英制
+func (s *SShopping) BuildItems(sources []*ShoppingSource, system string) []*model.ShoppingItem {
+	var keys []shoppingKey
+	items := make(map[shoppingKey]*model.ShoppingItem)
+	metric := make(map[shoppingKey]bool)
+
+	for _, source := range sources {
+		// 计算份数的缩放比例
+		factor := 1.0
+		if source.Servings > 0 {
+			base := source.Recipe.Servings
+			if base <= 0 {
+				base = 1
+			}
+			factor = float64(source.Servings) / float64(base)
+		}
+
+		for _, item := range ingredient.ParseAll(source.Recipe.Ingredients) {
+			if item.Name == "" {
+				continue
+			}
+
+			// 数量是范围时使用最大值，保证买够
+			quantity := item.Quantity
+			if item.QuantityMax > quantity {
+				quantity = item.QuantityMax
+			}
+			quantity, u := unit.Normalize(quantity*factor, item.Unit)
+
+			key := shoppingKey{name: ingredient.NormalizeName(item.Name), unit: u}
+			if _, ok := items[key]; !ok {
+				keys = append(keys, key)
+				items[key] = &model.ShoppingItem{Name: item.Name, Unit: u}
+				metric[key] = true
+			}
+			items[key].Quantity += quantity
+			// 只要有一个菜谱使用英制的单位，就使用英制输出
+			metric[key] = metric[key] && unit.IsMetric(item.Unit)
+		}
+	}
+
+	// 按照食材第一次出现的顺序输出，并转换为合适的单位
+	res := make([]*model.ShoppingItem, 0, len(keys))
+	for _, key := range keys {
+		item := items[key]
+		target := system
+		if target == "" {
+			target = unit.Imperial
+			if metric[key] {
+				target = unit.Metric
+			}
+		}
+		item.Quantity, item.Unit = unit.Humanize(item.Quantity, item.Unit, target)
+		if item.Quantity > 0 {
+			item.Quantity = ingredient.RoundQuantityIn(item.Quantity, item.Unit)
+		}
+		res = append(res, item)
+	}
+
+	return res
+}
+
+// CreateList 创建购物清单和清单中的每一项
+func (s *SShopping) CreateList(ctx context.Context, shoppingList *model.ShoppingList) error {
+	if err := dao.User().Shopping().CreateList(ctx, shoppingList); err != nil {
+		g.Logger.Errorf("create [shopping_list] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// GetLists 获取用户的购物清单，不包括清单中的每一项
+func (s *SShopping) GetLists(ctx context.Context, userId int64) ([]*model.ShoppingList, error) {
+	shoppingLists, err := dao.User().Shopping().GetListsByUser(ctx, userId)
+	if err != nil {
+		g.Logger.Errorf("query [shopping_list] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return shoppingLists, nil
+}
+
+// GetList 获取用户的一个购物清单，包括清单中的每一项
+func (s *SShopping) GetList(ctx context.Context, id, userId int64) (*model.ShoppingList, error) {
+	shoppingList, err := dao.User().Shopping().GetListById(ctx, id, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("shopping list not found")
+		}
+		g.Logger.Errorf("query [shopping_list] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	shoppingList.Items, err = dao.User().Shopping().GetItemsByListId(ctx, id)
+	if err != nil {
+		g.Logger.Errorf("query [shopping_item] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return shoppingList, nil
+}
+
+// CheckItem 将购物清单中的一项标记为已购买或者未购买
+func (s *SShopping) CheckItem(ctx context.Context, listId, itemId int64, checked bool) (*model.ShoppingItem, error) {
+	item, err := dao.User().Shopping().GetItemById(ctx, listId, itemId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("shopping item not found")
+		}
+		g.Logger.Errorf("query [shopping_item] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	if err = dao.User().Shopping().UpdateItemChecked(ctx, itemId, checked); err != nil {
+		g.Logger.Errorf("update [shopping_item] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+	item.Checked = checked
+
+	return item, nil
+}
+
+// DeleteList 删除购物清单和清单中的每一项
+func (s *SShopping) DeleteList(ctx context.Context, id int64) error {
+	if err := dao.User().Shopping().DeleteList(ctx, id); err != nil {
+		g.Logger.Errorf("delete [shopping_list] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
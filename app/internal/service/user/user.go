@@ -11,6 +11,7 @@ import (
 	"main/app/internal/dao"
 	"main/app/internal/model"
 	"main/utils/jwt"
+	"strings"
 	"time"
 )
 
@@ -121,3 +122,77 @@ func (s *SUser) GenerateToken(ctx context.Context, userSubject *model.UserSubjec
 	// 如果没有错误，返回生成的令牌
 	return tokenString, nil
 }
+
+// GetUser 获取用户的信息，不包括密码
+func (s *SUser) GetUser(ctx context.Context, userId int64) (*model.UserSubject, error) {
+	userSubject, err := dao.User().User().GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		g.Logger.Errorf("query [user_subject] record failed, err: %v", err)
+		return nil, fmt.Errorf("internal err")
+	}
+
+	return userSubject, nil
+}
+
+// GetUnits 获取用户默认的单位制，用户不存在时返回空字符串
+func (s *SUser) GetUnits(ctx context.Context, userId int64) (string, error) {
+	userSubject, err := s.GetUser(ctx, userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return userSubject.Units, nil
+}
+
+// UpdateUnits 更新用户默认的单位制
+func (s *SUser) UpdateUnits(ctx context.Context, userId int64, units string) error {
+	err := dao.User().User().UpdateUnits(ctx, userId, units)
+	if err != nil {
+		g.Logger.Errorf("update [user_subject] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// GetAllergens 获取用户的过敏原，用户不存在时返回空的列表
+func (s *SUser) GetAllergens(ctx context.Context, userId int64) ([]string, error) {
+	userSubject, err := s.GetUser(ctx, userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	return SplitAllergens(userSubject.Allergens), nil
+}
+
+// UpdateAllergens 更新用户的过敏原
+func (s *SUser) UpdateAllergens(ctx context.Context, userId int64, allergens []string) error {
+	err := dao.User().User().UpdateAllergens(ctx, userId, strings.Join(allergens, ","))
+	if err != nil {
+		g.Logger.Errorf("update [user_subject] record failed, err: %v", err)
+		return fmt.Errorf("internal err")
+	}
+
+	return nil
+}
+
+// SplitAllergens 将逗号分隔的过敏原转换为列表
+func SplitAllergens(allergens string) []string {
+	res := []string{}
+	for _, allergen := range strings.Split(allergens, ",") {
+		if allergen = strings.TrimSpace(allergen); allergen != "" {
+			res = append(res, allergen)
+		}
+	}
+
+	return res
+}
@@ -12,6 +12,30 @@ func (r *RecipeRouter) InitRecipeRouter(router *gin.RouterGroup) (R gin.IRoutes)
 	recipeApi := api.Recipe()
 	{
 		recipeRouter.GET("", recipeApi.Recipe().Search)
+		recipeRouter.GET("/categories", recipeApi.Recipe().Categories)
+		recipeRouter.GET("/keywords", recipeApi.Recipe().Keywords)
+		recipeRouter.GET("/recommended", recipeApi.Recipe().Recommended)
+		recipeRouter.GET("/:id", recipeApi.Recipe().Detail)
+		recipeRouter.GET("/:id/export", recipeApi.Export().Export)
+		recipeRouter.GET("/:id/similar", recipeApi.Recipe().Similar)
+		recipeRouter.GET("/:id/substitutions", recipeApi.Recipe().Substitutions)
+		recipeRouter.GET("/:id/steps", recipeApi.Cooking().Steps)
+		recipeRouter.GET("/:id/steps/:step", recipeApi.Cooking().Step)
+		recipeRouter.GET("/:id/reviews", recipeApi.Review().GetList)
+		recipeRouter.POST("/:id/reviews", recipeApi.Review().Create)
+		recipeRouter.PUT("/:id/reviews", recipeApi.Review().Update)
+		recipeRouter.DELETE("/:id/reviews", recipeApi.Review().Delete)
+		recipeRouter.POST("/:id/images", recipeApi.Image().Upload)
+		recipeRouter.GET("/:id/versions", recipeApi.Version().GetList)
+		recipeRouter.GET("/:id/versions/:version", recipeApi.Version().Detail)
+		recipeRouter.GET("/:id/versions/:version/diff", recipeApi.Version().Diff)
+		recipeRouter.POST("/:id/versions/:version/revert", recipeApi.Version().Revert)
+		recipeRouter.POST("/:id/fork", recipeApi.Manage().Fork)
+		recipeRouter.POST("/match", recipeApi.Recipe().Match)
+		recipeRouter.POST("/plan-day", recipeApi.Recipe().PlanDay)
+		recipeRouter.POST("", recipeApi.Manage().Create)
+		recipeRouter.PUT("/:id", recipeApi.Manage().Update)
+		recipeRouter.DELETE("/:id", recipeApi.Manage().Delete)
 	}
 
 	return recipeRouter
@@ -13,6 +13,9 @@ func InitRouter() *gin.Engine {
 	r.Use(middleware.ZapLogger(g.Logger), middleware.ZapRecovery(g.Logger, true))
 	r.Use(middleware.CorsByRules())
 
+	// 提供上传到本地的图片
+	r.Static(g.Config.Upload.GetRoute(), g.Config.Upload.GetPath())
+
 	// 创建一个新的路由组
 	routerGroup := new(Group)
 
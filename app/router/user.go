@@ -25,6 +25,18 @@ func (r *UserRouter) InitUserInfoRouter(router *gin.RouterGroup) (R gin.IRoutes)
 		userRouter.GET("/collection", userApi.Collect().GetList)
 		userRouter.POST("/collection", userApi.Collect().Create)
 		userRouter.DELETE("/collection", userApi.Collect().Delete)
+		userRouter.GET("/preference", userApi.Preference().Get)
+		userRouter.PUT("/preference", userApi.Preference().Update)
+		userRouter.GET("/meal-plan", userApi.Plan().Week)
+		userRouter.POST("/meal-plan", userApi.Plan().Create)
+		userRouter.POST("/meal-plan/copy", userApi.Plan().Copy)
+		userRouter.PUT("/meal-plan/:id", userApi.Plan().Update)
+		userRouter.DELETE("/meal-plan/:id", userApi.Plan().Delete)
+		userRouter.GET("/shopping-list", userApi.Shopping().GetList)
+		userRouter.POST("/shopping-list", userApi.Shopping().Create)
+		userRouter.GET("/shopping-list/:id", userApi.Shopping().Detail)
+		userRouter.DELETE("/shopping-list/:id", userApi.Shopping().Delete)
+		userRouter.PUT("/shopping-list/:id/items/:item", userApi.Shopping().CheckItem)
 	}
 
 	return userRouter
@@ -58,3 +58,27 @@ func ViperSetup(path ...string) {
 		panic(fmt.Errorf("unmarshal config failed, err: %v", err))
 	}
 }
+
+// SubstitutionSetup 函数加载食材替换的知识库，支持YAML和JSON格式，根据文件扩展名判断
+// 知识库不是必须的，加载失败时只记录日志，不提供替换建议
+func SubstitutionSetup() {
+	path := g.Config.SubstitutionPath
+	if path == "" {
+		g.Logger.Warn("substitution path is empty, skip loading substitutions")
+		return
+	}
+
+	v := viper.New()      // 创建一个新的viper实例
+	v.SetConfigFile(path) // 设置知识库的文件路径，文件类型由扩展名决定
+	if err := v.ReadInConfig(); err != nil {
+		g.Logger.Errorf("get substitution file failed, err: %v", err)
+		return
+	}
+
+	if err := v.Unmarshal(&g.Substitution); err != nil {
+		g.Logger.Errorf("unmarshal substitution failed, err: %v", err)
+		return
+	}
+
+	g.Logger.Infof("load %d substitution rules successfully", len(g.Substitution.Rules))
+}
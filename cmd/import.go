@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"main/app/command"
+	"main/boot"
+	"os"
+)
+
+// importRecipes 执行导入菜谱的子命令
+// 用法: main import [-c config.yaml] [-format jsonl|csv] [-dry-run] file...
+func importRecipes(args []string) {
+	var configPath, format string
+	var dryRun bool
+
+	// 解析子命令的参数
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&configPath, "c", "", "set config path")
+	fs.StringVar(&format, "format", "", "file format: jsonl or csv, detected by extension if empty")
+	fs.BoolVar(&dryRun, "dry-run", false, "validate files without writing to the database")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "usage: %s import [flags] file...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	// 如果没有指定文件，输出用法
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// 获取配置文件路径，优先级：命令行 > 环境变量 > 默认值
+	if configPath == "" {
+		configPath = os.Getenv("CONFIG_PATH")
+	}
+	if configPath == "" {
+		configPath = "manifest/config/config.yaml"
+	}
+
+	// 导入菜谱只需要MongoDB，写入前创建菜谱ID的唯一索引并初始化计数器
+	boot.ViperSetup(configPath)
+	boot.LoggerSetup()
+	if !dryRun {
+		boot.MongoDBSetup()
+		command.MigrateMongo()
+	}
+
+	ok := command.ImportRecipes(context.Background(), &command.ImportOptions{
+		Files:  fs.Args(),
+		Format: format,
+		DryRun: dryRun,
+		Out:    os.Stdout,
+	})
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -1,12 +1,28 @@
 package main
 
-import "main/boot"
+import (
+	"context"
+	"main/app/command"
+	"main/boot"
+	"os"
+)
 
 func main() {
+	// 如果第一个参数是子命令，执行子命令
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importRecipes(os.Args[2:])
+		return
+	}
+
 	boot.ViperSetup()
 	boot.LoggerSetup()
+	boot.SubstitutionSetup()
 	boot.MysqlDBSetup()
 	boot.MongoDBSetup()
 	boot.RedisSetup()
+
+	// 迁移数据库并启动后台任务，再启动服务器
+	command.Migrate()
+	command.StartJobs(context.Background())
 	boot.ServerSetup()
 }
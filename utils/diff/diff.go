@@ -0,0 +1,59 @@
+package diff
+
+// 定义差异的操作类型
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Edit 定义差异中的一行
+type Edit struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines 使用最长公共子序列比较两个列表，返回把a变成b的逐行差异
+// 同一位置既有删除又有插入时，删除排在插入前面
+func Lines(a, b []string) []*Edit {
+	// lcs[i][j]表示a[i:]和b[j:]的最长公共子序列的长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// 从头开始回溯，生成差异
+	edits := make([]*Edit, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, &Edit{Op: OpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, &Edit{Op: OpDelete, Text: a[i]})
+			i++
+		default:
+			edits = append(edits, &Edit{Op: OpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, &Edit{Op: OpDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, &Edit{Op: OpInsert, Text: b[j]})
+	}
+
+	return edits
+}
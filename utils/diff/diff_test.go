@@ -0,0 +1,37 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []Edit
+	}{
+		{"empty", nil, nil, []Edit{}},
+		{"equal", []string{"a", "b"}, []string{"a", "b"}, []Edit{{OpEqual, "a"}, {OpEqual, "b"}}},
+		{"insert", nil, []string{"a"}, []Edit{{OpInsert, "a"}}},
+		{"delete", []string{"a"}, nil, []Edit{{OpDelete, "a"}}},
+		{"insert in the middle", []string{"a", "c"}, []string{"a", "b", "c"},
+			[]Edit{{OpEqual, "a"}, {OpInsert, "b"}, {OpEqual, "c"}}},
+		{"delete before insert", []string{"a", "b", "c"}, []string{"a", "x", "c"},
+			[]Edit{{OpEqual, "a"}, {OpDelete, "b"}, {OpInsert, "x"}, {OpEqual, "c"}}},
+		{"move", []string{"a", "b", "c"}, []string{"b", "c", "a"},
+			[]Edit{{OpDelete, "a"}, {OpEqual, "b"}, {OpEqual, "c"}, {OpInsert, "a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []Edit{}
+			for _, e := range Lines(tt.a, tt.b) {
+				got = append(got, *e)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
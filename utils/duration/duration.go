@@ -0,0 +1,105 @@
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iso8601Regexp 匹配ISO-8601格式的时间段，例如PT1H30M、P1DT2H
+var iso8601Regexp = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// maxSeconds time.Duration能表示的最大秒数
+var maxSeconds = float64(math.MaxInt64 / int64(time.Second))
+
+// ErrInvalidDuration 时间段的格式无效
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// ParseISO8601 将ISO-8601格式的时间段解析为time.Duration，不支持年、月和周
+func ParseISO8601(s string) (time.Duration, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	match := iso8601Regexp.FindStringSubmatch(s)
+	// 如果格式不匹配，或者只有P和T，返回错误
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, ErrInvalidDuration
+	}
+
+	// 依次累加天、小时、分钟和秒
+	var d time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		// 超过time.Duration范围时返回错误
+		if v*float64(unit) > float64(math.MaxInt64)-float64(d) {
+			return 0, ErrInvalidDuration
+		}
+		d += time.Duration(v * float64(unit))
+	}
+
+	return d, nil
+}
+
+// FormatISO8601 将time.Duration格式化为ISO-8601格式的时间段，例如PT1H30M
+func FormatISO8601(d time.Duration) string {
+	// 负数的时间段没有意义，按0处理
+	if d <= 0 {
+		return "PT0S"
+	}
+
+	// 依次计算小时、分钟和秒
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	sec := d / time.Second
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if h > 0 {
+		_, _ = fmt.Fprintf(&b, "%dH", h)
+	}
+	if m > 0 {
+		_, _ = fmt.Fprintf(&b, "%dM", m)
+	}
+	if sec > 0 {
+		_, _ = fmt.Fprintf(&b, "%dS", sec)
+	}
+
+	return b.String()
+}
+
+// Parse 解析时间段，支持秒数(例如900)、Go格式(例如15m)和ISO-8601格式(例如PT15M)
+func Parse(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	// 纯数字按秒处理
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		// 拒绝负数、NaN、Inf和超过time.Duration范围的秒数
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) || v > maxSeconds {
+			return 0, ErrInvalidDuration
+		}
+		return time.Duration(v * float64(time.Second)), nil
+	}
+	// ISO-8601格式以P开头
+	if strings.HasPrefix(strings.ToUpper(s), "P") {
+		return ParseISO8601(s)
+	}
+	// 其他的按Go格式解析
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, ErrInvalidDuration
+	}
+	return d, nil
+}
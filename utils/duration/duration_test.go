@@ -0,0 +1,64 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"900", 15 * time.Minute, false},
+		{"90.5", 90*time.Second + 500*time.Millisecond, false},
+		{"15m", 15 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"PT15M", 15 * time.Minute, false},
+		{"pt1h30m", 90 * time.Minute, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"-5", 0, true},
+		{"-5m", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"-Inf", 0, true},
+		{"1e300", 0, true},
+		{"P", 0, true},
+		{"PT", 0, true},
+		{"P1W", 0, true},
+		{"P999999999D", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v, want %v, err %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatISO8601(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{-time.Minute, "PT0S"},
+		{45 * time.Second, "PT45S"},
+		{90 * time.Minute, "PT1H30M"},
+		{26*time.Hour + 5*time.Second, "PT26H5S"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatISO8601(tt.d); got != tt.want {
+			t.Errorf("FormatISO8601(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+		// 格式化的结果可以解析回原来的时间段
+		if got, err := ParseISO8601(tt.want); err != nil || (tt.d > 0 && got != tt.d) {
+			t.Errorf("ParseISO8601(%q) = %v, %v, want %v", tt.want, got, err, tt.d)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package duration
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Span 文本中的一个时间段，写成区间时Min和Max分别为区间的两端，否则两者相等
+type Span struct {
+	Text  string        // 时间段在文本中的原文
+	Start int           // 原文在文本中的起始位置
+	End   int           // 原文在文本中的结束位置
+	Min   time.Duration // 最短的时间
+	Max   time.Duration // 最长的时间
+}
+
+// number 匹配时间段中的数量，包括小数、分数、带分数和常用的英文数字
+const number = `(\d+\s+\d+/\d+|\d+/\d+|\d+(?:\.\d+)?|half\s+an?|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|fifteen|twenty|thirty|forty-five|forty|sixty)`
+
+var (
+	// textRegexp 匹配文本中的时间段，例如20 minutes、1 1/2 hours和10-15 mins
+	textRegexp = regexp.MustCompile(`(?i)\b` + number + `(?:\s*(?:-|–|to)\s*` + number + `)?\s*(hours?|hrs?|minutes?|mins?|seconds?|secs?)\b`)
+	// joinRegexp 匹配两个时间段之间可以合并的连接部分，例如1 hour 30 minutes和1 hour and 30 minutes
+	joinRegexp = regexp.MustCompile(`(?i)^(?:\s*,?\s*(?:and\s+)?)$`)
+)
+
+// numberWords 定义英文数字对应的数值
+var numberWords = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"fifteen": 15, "twenty": 20, "thirty": 30, "forty": 40, "forty-five": 45, "sixty": 60,
+}
+
+// FindAll 找出文本中所有的时间段，相邻的不同单位的时间段会合并为一个，例如1 hour 30 minutes
+func FindAll(text string) []*Span {
+	var spans []*Span
+	var lastUnit time.Duration
+	for _, m := range textRegexp.FindAllStringSubmatchIndex(text, -1) {
+		unit := textUnit(text[m[6]:m[7]])
+		min := parseNumber(text[m[2]:m[3]])
+		max := min
+		if m[4] >= 0 {
+			max = parseNumber(text[m[4]:m[5]])
+		}
+		// 无法解析的数量或者区间的两端颠倒时忽略
+		if min <= 0 || max < min {
+			continue
+		}
+		span := &Span{
+			Text:  text[m[0]:m[1]],
+			Start: m[0],
+			End:   m[1],
+			Min:   time.Duration(min * float64(unit)),
+			Max:   time.Duration(max * float64(unit)),
+		}
+
+		// 和前一个单位更大的时间段之间只有空白或者and时，合并为一个时间段
+		if n := len(spans); n > 0 {
+			prev := spans[n-1]
+			if lastUnit > unit && prev.Min == prev.Max && span.Min == span.Max && joinRegexp.MatchString(text[prev.End:span.Start]) {
+				prev.Text = text[prev.Start:span.End]
+				prev.End = span.End
+				prev.Min += span.Min
+				prev.Max += span.Max
+				lastUnit = unit
+				continue
+			}
+		}
+
+		spans = append(spans, span)
+		lastUnit = unit
+	}
+
+	return spans
+}
+
+// textUnit 获取时间单位对应的时长
+func textUnit(s string) time.Duration {
+	switch s = strings.ToLower(s); {
+	case strings.HasPrefix(s, "h"):
+		return time.Hour
+	case strings.HasPrefix(s, "m"):
+		return time.Minute
+	default:
+		return time.Second
+	}
+}
+
+// parseNumber 解析时间段中的数量，无法解析时返回0
+func parseNumber(s string) float64 {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if v, ok := numberWords[s]; ok {
+		return v
+	}
+	// half an hour这样的写法为0.5
+	if strings.HasPrefix(s, "half") {
+		return 0.5
+	}
+
+	// 带分数和分数，例如1 1/2和1/2
+	var whole float64
+	if fields := strings.Fields(s); len(fields) == 2 {
+		whole, _ = strconv.ParseFloat(fields[0], 64)
+		s = fields[1]
+	}
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0
+		}
+		return whole + n/d
+	}
+
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,48 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindAll(t *testing.T) {
+	type span struct {
+		text     string
+		min, max time.Duration
+	}
+	tests := []struct {
+		text string
+		want []span
+	}{
+		{"Simmer for 20 minutes.", []span{{"20 minutes", 20 * time.Minute, 20 * time.Minute}}},
+		{"Bake 1 hour 30 minutes", []span{{"1 hour 30 minutes", 90 * time.Minute, 90 * time.Minute}}},
+		{"Rest 1 hour and 15 mins", []span{{"1 hour and 15 mins", 75 * time.Minute, 75 * time.Minute}}},
+		{"Cook 10-15 mins", []span{{"10-15 mins", 10 * time.Minute, 15 * time.Minute}}},
+		{"Roast 1 1/2 hours", []span{{"1 1/2 hours", 90 * time.Minute, 90 * time.Minute}}},
+		{"Chill for half an hour", []span{{"half an hour", 30 * time.Minute, 30 * time.Minute}}},
+		{"Boil two to three minutes", []span{{"two to three minutes", 2 * time.Minute, 3 * time.Minute}}},
+		{"Fry 5 minutes, then bake 30 seconds", []span{
+			{"5 minutes", 5 * time.Minute, 5 * time.Minute},
+			{"30 seconds", 30 * time.Second, 30 * time.Second},
+		}},
+		{"Cook 15-10 minutes", nil},
+		{"Add 2 cups of flour", nil},
+	}
+
+	for _, tt := range tests {
+		got := FindAll(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("FindAll(%q) found %d spans, want %d", tt.text, len(got), len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			if got[i].Text != w.text || got[i].Min != w.min || got[i].Max != w.max {
+				t.Errorf("FindAll(%q)[%d] = %q %v-%v, want %q %v-%v",
+					tt.text, i, got[i].Text, got[i].Min, got[i].Max, w.text, w.min, w.max)
+			}
+			if tt.text[got[i].Start:got[i].End] != got[i].Text {
+				t.Errorf("FindAll(%q)[%d] position %d-%d does not match %q", tt.text, i, got[i].Start, got[i].End, got[i].Text)
+			}
+		}
+	}
+}
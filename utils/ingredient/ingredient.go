@@ -0,0 +1,332 @@
+package ingredient
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Ingredient 定义解析后的食材
+type Ingredient struct {
+	Raw         string  `json:"raw"`                    // 原始的食材
+	Quantity    float64 `json:"quantity"`               // 数量，没有数量时为0
+	QuantityMax float64 `json:"quantity_max,omitempty"` // 数量是范围时的最大值，例如2-3
+	Unit        string  `json:"unit"`                   // 规范化后的单位，没有单位时为空
+	Name        string  `json:"name"`                   // 食材的名称
+	Preparation string  `json:"preparation"`            // 处理方式和备注，例如chopped、to taste
+}
+
+// unitAliases 定义单位的别名和规范化后的单位
+var unitAliases = map[string]string{
+	"teaspoon": "teaspoon", "teaspoons": "teaspoon", "tsp": "teaspoon", "tsps": "teaspoon",
+	"tablespoon": "tablespoon", "tablespoons": "tablespoon", "tbsp": "tablespoon", "tbsps": "tablespoon",
+	"tbs": "tablespoon", "tbl": "tablespoon", "tb": "tablespoon",
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"fl oz": "fluid ounce", "fluid ounce": "fluid ounce", "fluid ounces": "fluid ounce",
+	"ounce": "ounce", "ounces": "ounce", "oz": "ounce",
+	"pound": "pound", "pounds": "pound", "lb": "pound", "lbs": "pound",
+	"gram": "gram", "grams": "gram", "g": "gram", "gr": "gram",
+	"kilogram": "kilogram", "kilograms": "kilogram", "kg": "kilogram", "kgs": "kilogram",
+	"milligram": "milligram", "milligrams": "milligram", "mg": "milligram",
+	"milliliter": "milliliter", "milliliters": "milliliter", "millilitre": "milliliter", "millilitres": "milliliter", "ml": "milliliter",
+	"liter": "liter", "liters": "liter", "litre": "liter", "litres": "liter", "l": "liter",
+	"pint": "pint", "pints": "pint", "pt": "pint",
+	"quart": "quart", "quarts": "quart", "qt": "quart",
+	"gallon": "gallon", "gallons": "gallon", "gal": "gallon",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"drop": "drop", "drops": "drop",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can",
+	"jar": "jar", "jars": "jar",
+	"bottle": "bottle", "bottles": "bottle",
+	"package": "package", "packages": "package", "pkg": "package", "pkgs": "package",
+	"packet": "packet", "packets": "packet",
+	"envelope": "envelope", "envelopes": "envelope",
+	"box": "box", "boxes": "box",
+	"bag": "bag", "bags": "bag",
+	"container": "container", "containers": "container",
+	"slice": "slice", "slices": "slice",
+	"piece": "piece", "pieces": "piece",
+	"stick": "stick", "sticks": "stick",
+	"bunch": "bunch", "bunches": "bunch",
+	"sprig": "sprig", "sprigs": "sprig",
+	"head": "head", "heads": "head",
+	"stalk": "stalk", "stalks": "stalk",
+	"handful": "handful", "handfuls": "handful",
+	"fillet": "fillet", "fillets": "fillet",
+}
+
+// caseSensitiveUnits 定义区分大小写的单位，大写的T是汤匙，小写的t是茶匙
+var caseSensitiveUnits = map[string]string{
+	"T": "tablespoon",
+	"t": "teaspoon",
+}
+
+// preparationWords 定义出现在食材名称前面的处理方式
+var preparationWords = map[string]bool{
+	"chopped": true, "minced": true, "diced": true, "sliced": true, "grated": true, "shredded": true,
+	"crushed": true, "peeled": true, "melted": true, "softened": true, "beaten": true, "cubed": true,
+	"halved": true, "quartered": true, "trimmed": true, "rinsed": true, "drained": true, "packed": true,
+	"sifted": true, "toasted": true, "cooked": true, "uncooked": true, "frozen": true, "thawed": true,
+	"julienned": true, "mashed": true, "pitted": true, "seeded": true, "cored": true, "zested": true,
+	"large": true, "medium": true, "small": true, "heaping": true, "level": true, "scant": true,
+}
+
+// unicodeFractions 定义Unicode分数字符和对应的分数
+var unicodeFractions = strings.NewReplacer(
+	"½", " 1/2", "⅓", " 1/3", "⅔", " 2/3", "¼", " 1/4", "¾", " 3/4",
+	"⅕", " 1/5", "⅖", " 2/5", "⅗", " 3/5", "⅘", " 4/5", "⅙", " 1/6", "⅚", " 5/6",
+	"⅛", " 1/8", "⅜", " 3/8", "⅝", " 5/8", "⅞", " 7/8", "⁄", "/",
+)
+
+var (
+	// parenRegexp 匹配括号中的内容
+	parenRegexp = regexp.MustCompile(`\(([^)]*)\)`)
+	// rangeRegexp 匹配数量的范围，例如2-3
+	rangeRegexp = regexp.MustCompile(`^([\d./]+)-([\d./]+)$`)
+	// numberUnitRegexp 匹配数量和单位连在一起的写法，例如200g
+	numberUnitRegexp = regexp.MustCompile(`^([\d.]+)([a-zA-Z]+)$`)
+)
+
+// Parse 解析一行食材，例如"1 1/2 cups chopped onion"
+func Parse(raw string) *Ingredient {
+	res := &Ingredient{Raw: raw}
+	var notes []string
+
+	// 规范化Unicode分数和空白
+	text := unicodeFractions.Replace(strings.TrimSpace(raw))
+
+	// 取出括号中的内容作为备注，例如"1 (14 ounce) can tomatoes"
+	for _, m := range parenRegexp.FindAllStringSubmatch(text, -1) {
+		if note := strings.TrimSpace(m[1]); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	text = parenRegexp.ReplaceAllString(text, " ")
+
+	// 逗号后面的内容是处理方式，例如"onion, finely chopped"
+	var suffix string
+	if i := strings.Index(text, ","); i >= 0 {
+		suffix = strings.TrimSpace(text[i+1:])
+		text = text[:i]
+	}
+
+	tokens := strings.Fields(text)
+
+	// 解析数量
+	tokens = parseQuantity(res, tokens)
+
+	// 解析单位，可能是两个单词，例如"fl oz"
+	if len(tokens) >= 2 {
+		if unit, ok := unitAliases[normalizeUnit(tokens[0]+" "+tokens[1])]; ok {
+			res.Unit = unit
+			tokens = tokens[2:]
+		}
+	}
+	if res.Unit == "" && len(tokens) >= 1 {
+		// 单个字母的单位只有在有数量时才可能是单位，避免把名称当作单位
+		word := normalizeUnit(tokens[0])
+		if unit, ok := unitAliases[word]; ok && (len(strings.TrimSuffix(tokens[0], ".")) > 1 || res.Quantity > 0) && len(tokens) > 1 {
+			res.Unit = unit
+			tokens = tokens[1:]
+		}
+	}
+	// 去掉单位后面的of，例如"a pinch of salt"
+	if len(tokens) > 1 && strings.ToLower(tokens[0]) == "of" {
+		tokens = tokens[1:]
+	}
+
+	// 解析名称前面的处理方式，例如"finely chopped onion"
+	var preps []string
+	for len(tokens) > 1 {
+		word := strings.ToLower(strings.Trim(tokens[0], ".;"))
+		if preparationWords[word] {
+			preps = append(preps, word)
+			tokens = tokens[1:]
+			continue
+		}
+		// 副词后面跟着处理方式，例如finely、thinly
+		if strings.HasSuffix(word, "ly") && len(tokens) > 2 && preparationWords[strings.ToLower(tokens[1])] {
+			preps = append(preps, word+" "+strings.ToLower(tokens[1]))
+			tokens = tokens[2:]
+			continue
+		}
+		// 连接词，例如"peeled and diced"
+		if word == "and" && len(preps) > 0 {
+			tokens = tokens[1:]
+			continue
+		}
+		break
+	}
+
+	name := strings.Join(tokens, " ")
+	// 名称后面的"to taste"是备注
+	if lower := strings.ToLower(name); strings.HasSuffix(lower, " to taste") {
+		name = strings.TrimSpace(name[:len(name)-len(" to taste")])
+		notes = append(notes, "to taste")
+	}
+	res.Name = strings.TrimSpace(name)
+
+	// 合并处理方式和备注
+	if len(preps) > 0 {
+		notes = append([]string{strings.Join(preps, " ")}, notes...)
+	}
+	if suffix != "" {
+		notes = append(notes, suffix)
+	}
+	res.Preparation = strings.Join(notes, ", ")
+
+	return res
+}
+
+// ParseAll 解析多行食材
+func ParseAll(raws []string) []*Ingredient {
+	res := make([]*Ingredient, 0, len(raws))
+	for _, raw := range raws {
+		res = append(res, Parse(raw))
+	}
+	return res
+}
+
+// NormalizeName 规范化食材的名称，用于比较和合并相同的食材，例如Tomatoes和tomato
+func NormalizeName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) == 0 {
+		return ""
+	}
+
+	// 把最后一个单词转换为单数
+	last := words[len(words)-1]
+	switch {
+	case strings.HasSuffix(last, "ies") && len(last) > 4:
+		last = strings.TrimSuffix(last, "ies") + "y"
+	case strings.HasSuffix(last, "oes") || strings.HasSuffix(last, "ches") || strings.HasSuffix(last, "shes"):
+		last = strings.TrimSuffix(last, "es")
+	case strings.HasSuffix(last, "s") && !strings.HasSuffix(last, "ss") && !strings.HasSuffix(last, "us") && len(last) > 3:
+		last = strings.TrimSuffix(last, "s")
+	}
+	words[len(words)-1] = last
+
+	return strings.Join(words, " ")
+}
+
+// NamePattern 将食材的名称转换为正则表达式，按整个单词匹配名称的单数和复数形式
+// 例如tomato匹配"2 tomatoes"，salt不匹配"unsalted butter"，名称为空时返回空字符串
+func NamePattern(name string) string {
+	words := strings.Fields(NormalizeName(name))
+	if len(words) == 0 {
+		return ""
+	}
+
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	// 最后一个单词允许复数形式
+	last := words[len(words)-1]
+	if strings.HasSuffix(last, "y") && len(last) > 3 {
+		last = strings.TrimSuffix(last, "y") + "(y|ies)"
+	} else {
+		last += "(s|es)?"
+	}
+	words[len(words)-1] = last
+
+	return `\b` + strings.Join(words, `[\s-]+`) + `\b`
+}
+
+// parseQuantity 从开头解析数量，返回剩余的单词
+func parseQuantity(res *Ingredient, tokens []string) []string {
+	if len(tokens) == 0 {
+		return tokens
+	}
+
+	// "a"或"an"后面跟着单位时表示1，例如"a pinch of salt"
+	if first := strings.ToLower(tokens[0]); (first == "a" || first == "an") && len(tokens) > 1 {
+		if _, ok := unitAliases[normalizeUnit(tokens[1])]; ok {
+			res.Quantity = 1
+			return tokens[1:]
+		}
+		return tokens
+	}
+
+	// 数量和单位连在一起，例如200g
+	if m := numberUnitRegexp.FindStringSubmatch(tokens[0]); m != nil {
+		if _, ok := unitAliases[strings.ToLower(m[2])]; ok {
+			if v, ok := parseNumber(m[1]); ok {
+				res.Quantity = v
+				return append([]string{m[2]}, tokens[1:]...)
+			}
+		}
+	}
+
+	// 数量的范围，例如2-3
+	if m := rangeRegexp.FindStringSubmatch(tokens[0]); m != nil {
+		from, ok1 := parseNumber(m[1])
+		to, ok2 := parseNumber(m[2])
+		if ok1 && ok2 {
+			res.Quantity, res.QuantityMax = from, to
+			return tokens[1:]
+		}
+	}
+
+	// 整数、小数、分数和带分数，例如1 1/2
+	i := 0
+	for i < len(tokens) && i < 2 {
+		v, ok := parseNumber(tokens[i])
+		if !ok {
+			break
+		}
+		// 第二个数字只能是分数
+		if i == 1 && !strings.Contains(tokens[i], "/") {
+			break
+		}
+		res.Quantity += v
+		i++
+	}
+	if i == 0 {
+		return tokens
+	}
+	tokens = tokens[i:]
+
+	// "2 to 3"或"2 - 3"形式的范围
+	if len(tokens) >= 2 && (strings.ToLower(tokens[0]) == "to" || tokens[0] == "-" || strings.ToLower(tokens[0]) == "or") {
+		if v, ok := parseNumber(tokens[1]); ok && v > res.Quantity {
+			res.QuantityMax = v
+			tokens = tokens[2:]
+		}
+	}
+
+	return tokens
+}
+
+// parseNumber 解析整数、小数和分数，拒绝负数、NaN和Inf，例如"nan bread"中的nan不是数量
+func parseNumber(s string) (float64, bool) {
+	var v float64
+	if parts := strings.Split(s, "/"); len(parts) == 2 {
+		n, err1 := strconv.ParseFloat(parts[0], 64)
+		d, err2 := strconv.ParseFloat(parts[1], 64)
+		if err1 != nil || err2 != nil || n < 0 || d <= 0 {
+			return 0, false
+		}
+		v = n / d
+	} else {
+		var err error
+		if v, err = strconv.ParseFloat(s, 64); err != nil || v < 0 {
+			return 0, false
+		}
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// normalizeUnit 规范化单位，去掉末尾的句点，区分大小写的单位先转换为规范化后的单位，其他的转为小写
+func normalizeUnit(s string) string {
+	s = strings.TrimSuffix(s, ".")
+	if unit, ok := caseSensitiveUnits[s]; ok {
+		return unit
+	}
+	return strings.ToLower(s)
+}
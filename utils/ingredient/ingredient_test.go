@@ -0,0 +1,104 @@
+package ingredient
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw         string
+		quantity    float64
+		quantityMax float64
+		unit        string
+		name        string
+	}{
+		{"1 1/2 cups chopped onion", 1.5, 0, "cup", "onion"},
+		{"200g flour", 200, 0, "gram", "flour"},
+		{"2-3 cloves garlic", 2, 3, "clove", "garlic"},
+		{"½ tsp salt", 0.5, 0, "teaspoon", "salt"},
+		{"a pinch of salt", 1, 0, "pinch", "salt"},
+		{"6 T butter", 6, 0, "tablespoon", "butter"},
+		{"1 t salt", 1, 0, "teaspoon", "salt"},
+		{"2 Tbsp. olive oil", 2, 0, "tablespoon", "olive oil"},
+		{"3 eggs", 3, 0, "", "eggs"},
+		{"T bone steak", 0, 0, "", "T bone steak"},
+		{"salt to taste", 0, 0, "", "salt"},
+		{"1 nan bread", 1, 0, "", "nan bread"},
+		{"nan bread", 0, 0, "", "nan bread"},
+		{"Inf", 0, 0, "", "Inf"},
+		{"infinity cups flour", 0, 0, "", "infinity cups flour"},
+		{"-1/2 cup milk", 0, 0, "", "-1/2 cup milk"},
+		{"NaN-3 eggs", 0, 0, "", "NaN-3 eggs"},
+		{"1e400 g sugar", 0, 0, "", "1e400 g sugar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			res := Parse(tt.raw)
+			if res.Quantity != tt.quantity || res.QuantityMax != tt.quantityMax {
+				t.Errorf("quantity = %v-%v, want %v-%v", res.Quantity, res.QuantityMax, tt.quantity, tt.quantityMax)
+			}
+			if res.Unit != tt.unit {
+				t.Errorf("unit = %q, want %q", res.Unit, tt.unit)
+			}
+			if res.Name != tt.name {
+				t.Errorf("name = %q, want %q", res.Name, tt.name)
+			}
+			// 解析的结果需要可以编码为JSON
+			if _, err := json.Marshal(res); err != nil {
+				t.Errorf("json.Marshal() err = %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Tomatoes", "tomato"},
+		{"cherries", "cherry"},
+		{"peaches", "peach"},
+		{"red onions", "red onion"},
+		{"glass", "glass"},
+		{"asparagus", "asparagus"},
+		{"  ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNamePattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"salt", "1 tsp salt", true},
+		{"salt", "2 tbsp unsalted butter", false},
+		{"tomato", "3 tomatoes, diced", true},
+		{"Tomatoes", "1 tomato", true},
+		{"berries", "1 cup mixed berries", true},
+		{"olive oil", "2 tbsp extra-virgin olive oil", true},
+		{"oil", "2 boiled eggs", false},
+		{"a.b", "axb", false},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile("(?i)" + NamePattern(tt.name))
+		if got := re.MatchString(tt.input); got != tt.want {
+			t.Errorf("NamePattern(%q) match %q = %v, want %v", tt.name, tt.input, got, tt.want)
+		}
+	}
+
+	if got := NamePattern(" "); got != "" {
+		t.Errorf(`NamePattern(" ") = %q, want ""`, got)
+	}
+}
@@ -0,0 +1,184 @@
+package ingredient
+
+import (
+	"main/utils/unit"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fraction 定义一个分数和它的文本
+type fraction struct {
+	value float64
+	text  string
+}
+
+// kitchenFractions 定义厨房常用的分数，按数值从小到大排列
+var kitchenFractions = []fraction{
+	{0, ""},
+	{1.0 / 8, "1/8"},
+	{1.0 / 4, "1/4"},
+	{1.0 / 3, "1/3"},
+	{3.0 / 8, "3/8"},
+	{1.0 / 2, "1/2"},
+	{5.0 / 8, "5/8"},
+	{2.0 / 3, "2/3"},
+	{3.0 / 4, "3/4"},
+	{7.0 / 8, "7/8"},
+	{1, ""},
+}
+
+// Scale 按比例缩放食材的数量，返回新的食材，数量会取整到厨房常用的分数
+func (i *Ingredient) Scale(factor float64) *Ingredient {
+	res := *i
+	if factor <= 0 || factor == 1 || i.Quantity <= 0 {
+		return &res
+	}
+
+	res.Quantity = RoundQuantityIn(i.Quantity*factor, i.Unit)
+	if i.QuantityMax > 0 {
+		res.QuantityMax = RoundQuantityIn(i.QuantityMax*factor, i.Unit)
+	}
+	res.Raw = res.String()
+
+	return &res
+}
+
+// Convert 将食材的数量和单位转换到指定的单位制，返回新的食材，无法转换的食材保持不变
+func (i *Ingredient) Convert(system string) *Ingredient {
+	res := *i
+	quantity, target, ok := unit.Convert(i.Quantity, i.Unit, system)
+	if !ok {
+		return &res
+	}
+
+	res.Quantity = RoundQuantityIn(quantity, target)
+	if i.QuantityMax > 0 {
+		res.QuantityMax = RoundQuantityIn(unit.ConvertRange(i.QuantityMax, i.Unit, target), target)
+	}
+	res.Unit = target
+	res.Raw = res.String()
+
+	return &res
+}
+
+// String 将食材格式化为一行文本，例如"1 1/2 cups onion, chopped"，没有数量时返回原始的食材
+func (i *Ingredient) String() string {
+	if i.Quantity <= 0 {
+		return i.Raw
+	}
+
+	parts := []string{FormatQuantityIn(i.Quantity, i.Unit)}
+	if i.QuantityMax > 0 {
+		parts[0] += "-" + FormatQuantityIn(i.QuantityMax, i.Unit)
+	}
+	if i.Unit != "" {
+		unit := i.Unit
+		if i.Quantity > 1 || i.QuantityMax > 1 {
+			unit = pluralize(unit)
+		}
+		parts = append(parts, unit)
+	}
+	if i.Name != "" {
+		parts = append(parts, i.Name)
+	}
+
+	res := strings.Join(parts, " ")
+	if i.Preparation != "" {
+		res += ", " + i.Preparation
+	}
+	return res
+}
+
+// RoundQuantity 将数量取整到厨房常用的分数，大于等于10时取整到整数
+func RoundQuantity(q float64) float64 {
+	if q <= 0 {
+		return 0
+	}
+	if q >= 10 {
+		return math.Round(q)
+	}
+
+	whole, frac := math.Modf(q)
+	value := nearestFraction(frac).value
+	// 太小的数量至少保留1/8
+	if whole == 0 && value == 0 {
+		value = kitchenFractions[1].value
+	}
+
+	return whole + value
+}
+
+// FormatQuantity 将数量格式化为带分数，例如1.5格式化为1 1/2
+func FormatQuantity(q float64) string {
+	if q <= 0 {
+		return "0"
+	}
+	if q >= 10 {
+		return strconv.FormatFloat(math.Round(q), 'f', -1, 64)
+	}
+
+	whole, frac := math.Modf(q)
+	f := nearestFraction(frac)
+	if f.value == 1 {
+		whole, f.text = whole+1, ""
+	}
+
+	switch {
+	case whole == 0 && f.text == "":
+		return kitchenFractions[1].text
+	case whole == 0:
+		return f.text
+	case f.text == "":
+		return strconv.FormatFloat(whole, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(whole, 'f', -1, 64) + " " + f.text
+	}
+}
+
+// RoundQuantityIn 按单位取整数量，公制的单位使用小数，其他单位使用厨房常用的分数
+func RoundQuantityIn(q float64, u string) float64 {
+	if !unit.IsMetric(u) {
+		return RoundQuantity(q)
+	}
+
+	switch {
+	case u == "liter" || u == "kilogram":
+		return math.Max(math.Round(q*100)/100, 0.01)
+	case q >= 100:
+		return math.Round(q/5) * 5
+	case q >= 10:
+		return math.Round(q)
+	default:
+		return math.Max(math.Round(q*2)/2, 0.5)
+	}
+}
+
+// FormatQuantityIn 按单位格式化数量，公制的单位使用小数，其他单位使用带分数
+func FormatQuantityIn(q float64, u string) string {
+	if !unit.IsMetric(u) {
+		return FormatQuantity(q)
+	}
+	return strconv.FormatFloat(q, 'f', -1, 64)
+}
+
+// nearestFraction 获取和小数部分最接近的厨房常用分数
+func nearestFraction(frac float64) fraction {
+	best := kitchenFractions[0]
+	for _, f := range kitchenFractions[1:] {
+		if math.Abs(f.value-frac) < math.Abs(best.value-frac) {
+			best = f
+		}
+	}
+	return best
+}
+
+// pluralize 获取单位的复数形式
+func pluralize(unit string) string {
+	for _, suffix := range []string{"s", "x", "ch", "sh"} {
+		if strings.HasSuffix(unit, suffix) {
+			return unit + "es"
+		}
+	}
+	return unit + "s"
+}
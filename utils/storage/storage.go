@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"main/utils/file"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage 定义保存上传文件的接口，key是使用"/"分隔的相对路径
+type Storage interface {
+	// Save 保存文件的内容，返回访问文件的URL
+	Save(ctx context.Context, key string, r io.Reader) (string, error)
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 获取访问文件的URL
+	URL(key string) string
+}
+
+// 定义本地存储和配置的类型
+type (
+	Local struct {
+		Config *LocalConfig
+	}
+
+	LocalConfig struct {
+		Root      string // 保存文件的根目录
+		UrlPrefix string // 访问文件的URL前缀
+	}
+)
+
+// NewLocal 函数创建一个保存到本地文件系统的存储
+func NewLocal(config *LocalConfig) *Local {
+	return &Local{
+		Config: config,
+	}
+}
+
+// Save 将文件保存到根目录下，先写入临时文件再重命名，避免读到不完整的文件
+func (l *Local) Save(_ context.Context, key string, r io.Reader) (string, error) {
+	dst, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	// 创建文件所在的目录
+	if err = file.IsNotExistMkDir(filepath.Dir(dst)); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+
+	return l.URL(key), nil
+}
+
+// Delete 删除根目录下的文件
+func (l *Local) Delete(_ context.Context, key string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// URL 使用URL前缀拼接文件的URL
+func (l *Local) URL(key string) string {
+	return strings.TrimRight(l.Config.UrlPrefix, "/") + "/" + strings.TrimLeft(path.Clean("/"+key), "/")
+}
+
+// path 获取文件在本地的路径，拒绝跳出根目录的key
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "\\") || clean != "/"+strings.TrimLeft(key, "/") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	return filepath.Join(l.Config.Root, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,62 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Flatten 将图片绘制到白色背景上，透明的部分使用白色填充，便于编码成JPEG
+// 同一张图片生成多个尺寸的缩略图时只需要调用一次，再把结果传给Resize
+func Flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+	return flat
+}
+
+// Resize 将Flatten处理后的图片按比例缩放到指定的宽度，宽度不小于原图时直接返回原图
+// 缩小时每个像素取原图对应区域的平均值
+func Resize(flat *image.RGBA, width int) *image.RGBA {
+	b := flat.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if width <= 0 || sw == 0 || sh == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if width >= sw {
+		return flat
+	}
+
+	// 按比例计算高度，至少为1个像素
+	height := max(sh*width/sw, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+
+			// 计算原图区域内像素的平均值
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := flat.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(flat.Pix[i])
+					g += uint64(flat.Pix[i+1])
+					bl += uint64(flat.Pix[i+2])
+					i += 4
+					n++
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(bl / n)
+			dst.Pix[j+3] = 0xff
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,44 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	// 左半边为红色，右半边为透明
+	src := image.NewNRGBA(image.Rect(10, 10, 410, 210))
+	for y := 10; y < 210; y++ {
+		for x := 10; x < 210; x++ {
+			src.Set(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+	flat := Flatten(src)
+
+	tests := []struct {
+		width, wantW, wantH int
+	}{
+		{100, 100, 50},
+		{1, 1, 1},
+		{400, 400, 200},
+		{800, 400, 200},
+		{0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		dst := Resize(flat, tt.width)
+		if b := dst.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("Resize(%d) size = %dx%d, want %dx%d", tt.width, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// 透明的部分使用白色填充
+	dst := Resize(flat, 100)
+	if got := dst.RGBAAt(10, 10); got != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("left pixel = %v, want red", got)
+	}
+	if got := dst.RGBAAt(90, 10); got != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("right pixel = %v, want white", got)
+	}
+}
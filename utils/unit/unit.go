@@ -0,0 +1,301 @@
+package unit
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Metric 公制，使用毫升、升、克、千克和摄氏度
+	Metric = "metric"
+	// Imperial 英制，使用茶匙、汤匙、杯、盎司、磅和华氏度
+	Imperial = "imperial"
+)
+
+// volumes 定义体积单位和对应的毫升数
+var volumes = map[string]float64{
+	"milliliter":  1,
+	"liter":       1000,
+	"teaspoon":    4.92892,
+	"tablespoon":  14.7868,
+	"fluid ounce": 29.5735,
+	"cup":         236.588,
+	"pint":        473.176,
+	"quart":       946.353,
+	"gallon":      3785.41,
+}
+
+// weights 定义重量单位和对应的克数
+var weights = map[string]float64{
+	"milligram": 0.001,
+	"gram":      1,
+	"kilogram":  1000,
+	"ounce":     28.3495,
+	"pound":     453.592,
+}
+
+// metricUnits 定义公制的单位
+var metricUnits = map[string]bool{
+	"milliliter": true,
+	"liter":      true,
+	"milligram":  true,
+	"gram":       true,
+	"kilogram":   true,
+}
+
+// IsValid 检查单位制是否有效
+func IsValid(system string) bool {
+	return system == Metric || system == Imperial
+}
+
+// IsMetric 检查单位是否是公制的单位
+func IsMetric(unit string) bool {
+	return metricUnits[unit]
+}
+
+// Convert 将数量和单位转换到指定的单位制，无法转换或者不需要转换时返回false
+// 单位需要是规范化后的单位，例如cup、gram
+func Convert(quantity float64, unit, system string) (float64, string, bool) {
+	if quantity <= 0 || !IsValid(system) {
+		return quantity, unit, false
+	}
+	// 已经是目标单位制的单位不需要转换
+	if _, ok := volumes[unit]; !ok {
+		if _, ok := weights[unit]; !ok {
+			return quantity, unit, false
+		}
+	}
+	if IsMetric(unit) == (system == Metric) {
+		return quantity, unit, false
+	}
+
+	// 体积
+	if ml, ok := volumes[unit]; ok {
+		ml *= quantity
+		target := pickVolume(ml, system)
+		return ml / volumes[target], target, true
+	}
+
+	// 重量
+	g := weights[unit] * quantity
+	target := pickWeight(g, system)
+	return g / weights[target], target, true
+}
+
+// Normalize 将体积转换为毫升，将重量转换为克，其他单位保持不变
+func Normalize(quantity float64, unit string) (float64, string) {
+	if v, ok := volumes[unit]; ok {
+		return quantity * v, "milliliter"
+	}
+	if v, ok := weights[unit]; ok {
+		return quantity * v, "gram"
+	}
+	return quantity, unit
+}
+
+// Humanize 将毫升数或者克数转换为指定单位制中合适的单位，其他单位保持不变
+func Humanize(quantity float64, unit, system string) (float64, string) {
+	if !IsValid(system) {
+		return quantity, unit
+	}
+	switch unit {
+	case "milliliter":
+		target := pickVolume(quantity, system)
+		return quantity / volumes[target], target
+	case "gram":
+		target := pickWeight(quantity, system)
+		return quantity / weights[target], target
+	}
+	return quantity, unit
+}
+
+// ConvertRange 使用和最小值相同的单位转换数量范围的最大值
+func ConvertRange(quantity float64, unit, target string) float64 {
+	if v, ok := volumes[unit]; ok {
+		return quantity * v / volumes[target]
+	}
+	if v, ok := weights[unit]; ok {
+		return quantity * v / weights[target]
+	}
+	return quantity
+}
+
+// pickVolume 根据毫升数选择合适的体积单位
+func pickVolume(ml float64, system string) string {
+	if system == Metric {
+		if ml >= 1000 {
+			return "liter"
+		}
+		return "milliliter"
+	}
+
+	switch {
+	case ml < volumes["tablespoon"]:
+		return "teaspoon"
+	case ml < volumes["cup"]/4:
+		return "tablespoon"
+	case ml < volumes["quart"]*1.5:
+		return "cup"
+	default:
+		return "quart"
+	}
+}
+
+// pickWeight 根据克数选择合适的重量单位
+func pickWeight(g float64, system string) string {
+	if system == Metric {
+		if g >= 1000 {
+			return "kilogram"
+		}
+		return "gram"
+	}
+
+	if g < weights["pound"] {
+		return "ounce"
+	}
+	return "pound"
+}
+
+// CelsiusToFahrenheit 将摄氏度转换为华氏度
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// FahrenheitToCelsius 将华氏度转换为摄氏度
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// temperature 匹配文本中的温度，例如350°F、180 °C、350F和350 degrees Fahrenheit
+const temperature = `(\d+(?:\.\d+)?)(\s*(?:°|º|(?i:degrees?|deg\.?))\s*|)(F|C|(?i:fahrenheit|celsius))\b`
+
+var (
+	// temperatureRegexp 匹配一个温度
+	temperatureRegexp = regexp.MustCompile(temperature)
+	// temperaturePairRegexp 匹配同时写了两种单位制的温度，例如350°F (175°C)和350°F/175°C
+	temperaturePairRegexp = regexp.MustCompile(temperature + `\s*(?:\(\s*` + temperature + `\s*\)|/\s*` + temperature + `)`)
+)
+
+// ConvertTemperatures 将文本中的温度转换到指定的单位制，烤箱的温度取整到常用的刻度
+func ConvertTemperatures(text, system string) string {
+	if !IsValid(system) {
+		return text
+	}
+
+	// 同时写了两种单位制的温度，只保留目标单位制的温度
+	text = temperaturePairRegexp.ReplaceAllStringFunc(text, func(s string) string {
+		temps := temperatureRegexp.FindAllStringSubmatch(s, 2)
+		if isCelsius(temps[1][3]) == (system == Metric) {
+			return temps[1][0]
+		}
+		return temps[0][0]
+	})
+
+	return temperatureRegexp.ReplaceAllStringFunc(text, func(s string) string {
+		return convertTemperature(s, system)
+	})
+}
+
+// Temperature 文本中的一个温度，同时给出摄氏度和华氏度
+type Temperature struct {
+	Text       string  // 温度在文本中的原文
+	Celsius    float64 // 摄氏度
+	Fahrenheit float64 // 华氏度
+}
+
+// FindTemperatures 找出文本中所有的温度，同时写了两种单位制的温度只算作一个，并使用原文中的两个数值
+func FindTemperatures(text string) []*Temperature {
+	// 记录同时写了两种单位制的温度的位置
+	pairs := temperaturePairRegexp.FindAllStringIndex(text, -1)
+	pairOf := func(start int) []int {
+		for _, p := range pairs {
+			if start >= p[0] && start < p[1] {
+				return p
+			}
+		}
+		return nil
+	}
+
+	var temps []*Temperature
+	lastPair := -1 // 最后一个温度所在的两种单位制的温度的起始位置
+	for _, m := range temperatureRegexp.FindAllStringSubmatchIndex(text, -1) {
+		v, err := strconv.ParseFloat(text[m[2]:m[3]], 64)
+		if err != nil {
+			continue
+		}
+		celsius := isCelsius(text[m[6]:m[7]])
+		pair := pairOf(m[0])
+
+		// 括号中或者斜线后的温度使用原文的数值替换换算的数值
+		if pair != nil && pair[0] != m[0] {
+			if pair[0] != lastPair {
+				continue
+			}
+			last := temps[len(temps)-1]
+			if celsius {
+				last.Celsius = v
+			} else {
+				last.Fahrenheit = v
+			}
+			continue
+		}
+		// 没有度数符号时只把较高的数值视为温度
+		if m[4] == m[5] && v < 100 {
+			continue
+		}
+
+		temp := &Temperature{Text: text[m[0]:m[1]]}
+		if pair != nil {
+			temp.Text = text[pair[0]:pair[1]]
+			lastPair = pair[0]
+		}
+		if celsius {
+			temp.Celsius, temp.Fahrenheit = v, roundTo(CelsiusToFahrenheit(v), 25, 200)
+		} else {
+			temp.Celsius, temp.Fahrenheit = roundTo(FahrenheitToCelsius(v), 10, 100), v
+		}
+		temps = append(temps, temp)
+	}
+
+	return temps
+}
+
+// convertTemperature 转换一个温度
+func convertTemperature(s, system string) string {
+	m := temperatureRegexp.FindStringSubmatch(s)
+	if m == nil || isCelsius(m[3]) == (system == Metric) {
+		return s
+	}
+
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return s
+	}
+	// 没有度数符号时只把较高的数值视为温度，避免把2C这样的写法当作温度
+	if m[2] == "" && v < 100 {
+		return s
+	}
+
+	// 烤箱的摄氏度取整到10度，华氏度取整到25度，较低的温度取整到5度
+	if system == Metric {
+		c := FahrenheitToCelsius(v)
+		return strconv.FormatFloat(roundTo(c, 10, 100), 'f', -1, 64) + "°C"
+	}
+	f := CelsiusToFahrenheit(v)
+	return strconv.FormatFloat(roundTo(f, 25, 200), 'f', -1, 64) + "°F"
+}
+
+// roundTo 将数值取整，大于等于阈值时取整到step，否则取整到5
+func roundTo(v, step, threshold float64) float64 {
+	if v < threshold {
+		step = 5
+	}
+	return math.Round(v/step) * step
+}
+
+// isCelsius 检查温度的单位是否是摄氏度
+func isCelsius(unit string) bool {
+	return strings.EqualFold(unit, "C") || strings.EqualFold(unit, "celsius")
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		unit     string
+		system   string
+		want     float64
+		wantUnit string
+		ok       bool
+	}{
+		{2, "cup", Metric, 473.176, "milliliter", true},
+		{5, "quart", Metric, 4.73177, "liter", true},
+		{100, "gram", Imperial, 3.5274, "ounce", true},
+		{500, "gram", Imperial, 1.10231, "pound", true},
+		{2, "kilogram", Imperial, 4.40925, "pound", true},
+		{1, "cup", Imperial, 1, "cup", false},
+		{250, "gram", Metric, 250, "gram", false},
+		{2, "clove", Metric, 2, "clove", false},
+		{0, "cup", Metric, 0, "cup", false},
+		{1, "cup", "si", 1, "cup", false},
+	}
+
+	for _, tt := range tests {
+		got, gotUnit, ok := Convert(tt.quantity, tt.unit, tt.system)
+		if math.Abs(got-tt.want) > 1e-3 || gotUnit != tt.wantUnit || ok != tt.ok {
+			t.Errorf("Convert(%v, %q, %q) = %v, %q, %v, want %v, %q, %v",
+				tt.quantity, tt.unit, tt.system, got, gotUnit, ok, tt.want, tt.wantUnit, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeAndHumanize(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		unit     string
+		system   string
+		want     float64
+		wantUnit string
+	}{
+		{2, "tablespoon", Imperial, 2, "tablespoon"},
+		{3, "cup", Metric, 709.764, "milliliter"},
+		{6, "cup", Metric, 1.41953, "liter"},
+		{1, "teaspoon", Imperial, 1, "teaspoon"},
+		{20, "ounce", Imperial, 1.25, "pound"},
+		{1500, "gram", Metric, 1.5, "kilogram"},
+		{3, "clove", Metric, 3, "clove"},
+	}
+
+	for _, tt := range tests {
+		q, u := Normalize(tt.quantity, tt.unit)
+		got, gotUnit := Humanize(q, u, tt.system)
+		if math.Abs(got-tt.want) > 1e-3 || gotUnit != tt.wantUnit {
+			t.Errorf("Humanize(Normalize(%v, %q), %q) = %v, %q, want %v, %q",
+				tt.quantity, tt.unit, tt.system, got, gotUnit, tt.want, tt.wantUnit)
+		}
+	}
+}
+
+func TestConvertTemperatures(t *testing.T) {
+	tests := []struct {
+		text   string
+		system string
+		want   string
+	}{
+		{"Preheat the oven to 350°F.", Metric, "Preheat the oven to 180°C."},
+		{"Bake at 180°C", Imperial, "Bake at 350°F"},
+		{"Heat to 400 degrees Fahrenheit", Metric, "Heat to 200°C"},
+		{"Bake at 350°F (175°C)", Metric, "Bake at 175°C"},
+		{"Bake at 350°F/175°C", Imperial, "Bake at 350°F"},
+		{"Add 2C of water", Imperial, "Add 2C of water"},
+		{"Bake at 350°F", Imperial, "Bake at 350°F"},
+		{"Bake at 350°F", "", "Bake at 350°F"},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertTemperatures(tt.text, tt.system); got != tt.want {
+			t.Errorf("ConvertTemperatures(%q, %q) = %q, want %q", tt.text, tt.system, got, tt.want)
+		}
+	}
+}
+
+func TestFindTemperatures(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Temperature
+	}{
+		{"Bake at 350°F (175°C) for 20 minutes", []Temperature{{"350°F (175°C)", 175, 350}}},
+		{"Heat the oil to 200C", []Temperature{{"200C", 200, 400}}},
+		{"Roast at 425°F, then lower to 350°F", []Temperature{{"425°F", 220, 425}, {"350°F", 180, 350}}},
+		{"Add 2C of water", nil},
+	}
+
+	for _, tt := range tests {
+		got := FindTemperatures(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("FindTemperatures(%q) found %d temperatures, want %d", tt.text, len(got), len(tt.want))
+			continue
+		}
+		for i, w := range tt.want {
+			if *got[i] != w {
+				t.Errorf("FindTemperatures(%q)[%d] = %+v, want %+v", tt.text, i, *got[i], w)
+			}
+		}
+	}
+}